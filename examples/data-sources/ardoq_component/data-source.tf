@@ -1,8 +1,24 @@
-data "ardoq-component" "someserver" {
+data "ardoq_component" "someserver" {
     root_workspace = "<id>"
     name = "SomeServerName"
 }
 
 output "someserver" {
     value = data.ardoq_component.someserver
-}
\ No newline at end of file
+}
+
+# names don't have to be unique, the path of a component is
+data "ardoq_component" "checkout" {
+  root_workspace = "<id>"
+  path           = "Platform/Payments/Checkout API"
+}
+
+# or look up a component by the value of a custom field
+data "ardoq_component" "crm" {
+  root_workspace = "<id>"
+
+  field {
+    name  = "application_id"
+    value = "APP-0042"
+  }
+}
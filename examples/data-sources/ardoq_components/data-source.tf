@@ -12,4 +12,11 @@ data "ardoq_components" "all" {
 # output all components
 output "all_components" {
   value = data.ardoq_components.all.components
-}
\ No newline at end of file
+}
+
+# returns the applications under a capability
+data "ardoq_components" "applications" {
+  root_workspace = data.ardoq_workspace.someworkspace.id
+  type_name      = "Application"
+  parent         = "<capability id>"
+}
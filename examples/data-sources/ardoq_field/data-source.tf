@@ -0,0 +1,5 @@
+# returns the field with the name "owner" of a model
+data "ardoq_field" "owner" {
+  name  = "owner"
+  model = "<model id>"
+}
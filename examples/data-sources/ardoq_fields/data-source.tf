@@ -0,0 +1,5 @@
+# returns the fields which apply to a component type, including the global fields
+data "ardoq_fields" "application" {
+  model          = "<model id>"
+  component_type = "<component type id>"
+}
@@ -1,8 +1,18 @@
-# returns all workspaces in workspace
-data "ardoq_models" "all" {
+# returns a model by its name
+data "ardoq_model" "apm" {
+  name = "Application Portfolio Management"
 }
 
-# output all components
-output "all_models" {
-  value = data.ardoq_models.all
-}
\ No newline at end of file
+# returns the model a workspace is based on
+data "ardoq_workspace" "someworkspace" {
+  name = "Some workspace"
+}
+
+data "ardoq_model" "someworkspace" {
+  workspace_id = data.ardoq_workspace.someworkspace.id
+}
+
+# output the id of the component type "Application"
+output "application_type_id" {
+  value = data.ardoq_model.someworkspace.component_types["Application"]
+}
@@ -9,7 +9,12 @@ data "ardoq_references" "all" {
   root_workspace = data.ardoq_workspace.someworkspace.id
 }
 
-# output all components
+# output all references
 output "all_references" {
-  value = data.ardoq_references.all.components
-}
\ No newline at end of file
+  value = data.ardoq_references.all.references
+}
+
+# returns the references from a component
+data "ardoq_references" "outgoing" {
+  source = "<component id>"
+}
@@ -0,0 +1,40 @@
+# read the applications from a CSV with the columns Name, Type, Parent and Owner
+data "ardoq_tabular_source" "applications" {
+  path = "${path.module}/applications.csv"
+
+  column {
+    name      = "Name"
+    attribute = "name"
+  }
+
+  column {
+    name      = "Type"
+    attribute = "type_name"
+    required  = true
+  }
+
+  column {
+    name      = "Parent"
+    attribute = "parent_name"
+  }
+
+  column {
+    name  = "Owner"
+    field = "owner"
+  }
+}
+
+# create all components with a few requests
+resource "ardoq_component_set" "applications" {
+  root_workspace = "<workspace id>"
+
+  dynamic "component" {
+    for_each = data.ardoq_tabular_source.applications.components
+    content {
+      name        = component.value.name
+      type_name   = component.value.type_name
+      parent_name = component.value.parent_name
+      fields      = component.value.fields
+    }
+  }
+}
@@ -5,4 +5,9 @@ data "ardoq_workspace" "someworkspace" {
 
 output "workspace_output" {
   value = data.ardoq_workspace.someworkspace
-}
\ No newline at end of file
+}
+
+# names don't have to be unique, request details for a workspace by its id
+data "ardoq_workspace" "byid" {
+  id = "<workspace id>"
+}
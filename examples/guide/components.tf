@@ -1,11 +1,12 @@
 locals {
-  componentscsv = csvdecode(file("${path.module}/guide.csv"))
-  components    = { for component in local.components : component.name => component }
+  componentscsv = csvdecode(file("${path.module}/components.csv"))
+  components    = { for component in local.componentscsv : component.name => component }
 }
 
 resource "ardoq_component" "component" {
   for_each = local.components
 
-  name = each.key
-  description = each.value.description
-}
\ No newline at end of file
+  root_workspace = "<workspace id>"
+  name           = each.key
+  description    = each.value.description
+}
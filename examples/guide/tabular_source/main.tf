@@ -0,0 +1,22 @@
+data "ardoq_tabular_source" "components" {
+  path           = "${path.module}/components.csv"
+  root_workspace = "<workspace id>"
+
+  column {
+    name      = "name"
+    attribute = "name"
+  }
+
+  column {
+    name      = "description"
+    attribute = "description"
+  }
+}
+
+resource "ardoq_component" "component" {
+  for_each = { for component in data.ardoq_tabular_source.components.components : component.name => component }
+
+  root_workspace = each.value.root_workspace
+  name           = each.key
+  description    = each.value.description
+}
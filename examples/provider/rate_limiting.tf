@@ -0,0 +1,4 @@
+provider "ardoq" {
+  requests_per_second     = 5
+  max_concurrent_requests = 4
+}
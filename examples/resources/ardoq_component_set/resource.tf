@@ -0,0 +1,29 @@
+# manage all applications of a workspace with a few requests
+resource "ardoq_component_set" "applications" {
+  root_workspace = "<workspace id>"
+
+  component {
+    name      = "CRM"
+    type_name = "Application"
+    fields = {
+      owner = "Sales"
+    }
+  }
+
+  component {
+    name        = "CRM Database"
+    parent_name = "CRM"
+  }
+
+  component {
+    name      = "ERP"
+    type_name = "Application"
+  }
+}
+
+# the ids of the components can be used to create references, the workspaces are read from the components
+resource "ardoq_reference" "crm_erp" {
+  source    = ardoq_component_set.applications.ids["CRM"]
+  target    = ardoq_component_set.applications.ids["ERP"]
+  type_name = "Implicit"
+}
@@ -0,0 +1,18 @@
+resource "ardoq_field" "lifecycle" {
+  name           = "lifecycle"
+  label          = "Lifecycle"
+  type           = "List"
+  model          = ardoq_model.application_portfolio.id
+  component_type = [ardoq_model.application_portfolio.component_types["Application"]]
+  options        = ["Plan", "Active", "Retired"]
+}
+
+# referring to the name of the field makes sure the field exists before the component is created
+resource "ardoq_component" "crm" {
+  root_workspace = ardoq_workspace.applications.id
+  name           = "CRM"
+  type_id        = ardoq_model.application_portfolio.component_types["Application"]
+  fields = {
+    (ardoq_field.lifecycle.name) = "Active"
+  }
+}
@@ -0,0 +1,38 @@
+resource "ardoq_model" "application_portfolio" {
+  name        = "Application Portfolio"
+  description = "Applications and the servers they run on"
+
+  component_type {
+    name  = "Application"
+    color = "#1f78b4"
+    icon  = "application"
+
+    # component types can be nested
+    component_type {
+      name = "Module"
+    }
+  }
+
+  component_type {
+    name  = "Server"
+    shape = "rect"
+  }
+
+  reference_type {
+    name        = "Runs on"
+    line        = "solid"
+    return_name = "Hosts"
+  }
+}
+
+resource "ardoq_workspace" "applications" {
+  name            = "Applications"
+  component_model = ardoq_model.application_portfolio.id
+}
+
+# use the computed component_types to set the type of a component
+resource "ardoq_component" "crm" {
+  root_workspace = ardoq_workspace.applications.id
+  name           = "CRM"
+  type_id        = ardoq_model.application_portfolio.component_types["Application"]
+}
@@ -0,0 +1,12 @@
+# create a workspace based on an existing model
+resource "ardoq_workspace" "applications" {
+  name            = "Applications"
+  description     = "All applications of the organization"
+  component_model = "<model id>"
+}
+
+# components can be created in the new workspace
+resource "ardoq_component" "crm" {
+  root_workspace = ardoq_workspace.applications.id
+  name           = "CRM"
+}
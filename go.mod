@@ -3,8 +3,11 @@ module github.com/mories76/terraform-provider-ardoq
 go 1.16
 
 require (
+	github.com/dghubble/sling v1.4.1
 	github.com/hashicorp/errwrap v1.1.0
 	github.com/hashicorp/terraform-plugin-docs v0.18.0
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.32.0
+	github.com/mitchellh/mapstructure v1.5.0
 	github.com/mories76/ardoq-client-go v0.0.12
+	golang.org/x/time v0.3.0
 )
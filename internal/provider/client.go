@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/dghubble/sling"
+	"github.com/mitchellh/mapstructure"
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+// apiClient is the meta object handed to every resource and data source.
+// It embeds the ardoq-client-go client and adds the endpoints the provider needs
+// which the upstream client does not implement (yet), like managing workspaces.
+type apiClient struct {
+	ardoq.Client
+
+	baseURI    string
+	apiKey     string
+	org        string
+	version    string
+	httpClient *http.Client
+
+	// optimisticConcurrency sends the version from the state with updates, see expectVersion
+	optimisticConcurrency bool
+}
+
+// newAPIClient returns a client which sends all requests through the transport, i.e. to retry them
+func newAPIClient(baseURI, apiKey, org, version string, transport http.RoundTripper) (*apiClient, error) {
+	c, err := ardoq.NewRestClient(baseURI, apiKey, org, version)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultClientTransports.register(baseURI, apiKey, org, transport)
+
+	return &apiClient{
+		Client:     c,
+		baseURI:    baseURI,
+		apiKey:     apiKey,
+		org:        org,
+		version:    version,
+		httpClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// restClient mirrors the request setup of the upstream client, so both send the same headers and org
+func (c *apiClient) restClient() *sling.Sling {
+	type OrgSearchQuery struct {
+		Org string `url:"org,omitempty"`
+	}
+
+	return sling.New().Doer(c.httpClient).Base(c.baseURI).
+		Set("User-Agent", fmt.Sprintf("%s (%s)", ardoq.UserAgentPrefix, c.version)).
+		Set("Authorization", fmt.Sprintf("Token token=%s", c.apiKey)).ResponseDecoder(ardoqDecoder{}).
+		QueryStruct(&OrgSearchQuery{Org: c.org})
+}
+
+// receive sends the request with the context, so canceling it stops waiting for a retry or a free slot,
+// decodes a successful response into res and returns an *ardoq.Error for any non 2xx response,
+// so isAPIErrorWithCode works for both clients
+func receive(ctx context.Context, s *sling.Sling, res interface{}, action string) error {
+	errResponse := new(ardoq.Error)
+
+	req, err := s.Request()
+	if err != nil {
+		return fmt.Errorf("could not %s: %w", action, err)
+	}
+
+	resp, err := s.Do(req.WithContext(ctx), res, errResponse)
+	if err != nil {
+		return fmt.Errorf("could not %s: %w", action, err)
+	}
+	// sling skips decoding of empty bodies, so check the status code instead of errResponse.NotOk()
+	if code := resp.StatusCode; code < 200 || code > 299 {
+		errResponse.Code = code
+		return errResponse
+	}
+
+	return nil
+}
+
+// ardoqDecoder decodes the JSON response with mapstructure, so all unknown
+// attributes end up in the ",remain" Fields of the response struct
+type ardoqDecoder struct{}
+
+func (a ardoqDecoder) Decode(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// for errors the decoded value is always an *ardoq.Error, keep the raw body
+	// as message when the error is not JSON, i.e. from a proxy in front of Ardoq
+	if code := resp.StatusCode; code < 200 || code > 299 {
+		errResponse := v.(*ardoq.Error)
+		if json.Unmarshal(body, errResponse) != nil {
+			errResponse.Message = string(body)
+		}
+		return nil
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return err
+	}
+
+	return mapstructure.WeakDecode(data, v)
+}
+
+// ardoqBodyProvider encodes the request as JSON, and merges the custom fields
+// into the same JSON object, this is how Ardoq expects custom fields to be sent
+type ardoqBodyProvider struct {
+	request interface{}
+	fields  map[string]interface{}
+}
+
+func (a ardoqBodyProvider) ContentType() string {
+	return "application/json"
+}
+
+func (a ardoqBodyProvider) Body() (io.Reader, error) {
+	requestJSON, err := json.Marshal(a.request)
+	if err != nil {
+		return nil, err
+	}
+
+	flatRequest := make(map[string]interface{})
+	if err := json.Unmarshal(requestJSON, &flatRequest); err != nil {
+		return nil, err
+	}
+
+	for k, v := range a.fields {
+		flatRequest[k] = v
+	}
+
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(flatRequest); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+)
+
+// batchClient applies many creates, updates and deletes with one request, using the batch API of Ardoq
+type batchClient interface {
+	Apply(ctx context.Context, req batchRequest) (*batchResponse, error)
+}
+
+// batchRequest is the payload of a batch, the parent of a component must exist before the batch is applied
+// URL: POST /api/v2/batch
+type batchRequest struct {
+	Components batchOperations `json:"components"`
+}
+
+type batchOperations struct {
+	Create []batchCreate `json:"create,omitempty"`
+	Update []batchUpdate `json:"update,omitempty"`
+	Delete []batchDelete `json:"delete,omitempty"`
+}
+
+// batchCreate creates a component, the batchId identifies the component in the response
+type batchCreate struct {
+	BatchID string                 `json:"batchId"`
+	Body    map[string]interface{} `json:"body"`
+}
+
+// batchUpdate updates a component, attributes which are not in the body are left unchanged
+type batchUpdate struct {
+	ID   string                 `json:"id"`
+	Body map[string]interface{} `json:"body"`
+}
+
+type batchDelete struct {
+	ID string `json:"id"`
+}
+
+// batchResponse is the result of a batch
+type batchResponse struct {
+	Components struct {
+		Created []struct {
+			BatchID string `mapstructure:"batchId"`
+			ID      string `mapstructure:"id"`
+		} `mapstructure:"created"`
+		Updated []struct {
+			ID string `mapstructure:"id"`
+		} `mapstructure:"updated"`
+		Deleted []struct {
+			ID string `mapstructure:"id"`
+		} `mapstructure:"deleted"`
+	} `mapstructure:"components"`
+}
+
+type restBatchClient struct {
+	client *apiClient
+}
+
+var _ batchClient = &restBatchClient{}
+
+// Batch returns a batchClient for applying many changes to components at once
+func (c *apiClient) Batch() batchClient {
+	return &restBatchClient{client: c}
+}
+
+// Apply applies a batch, Ardoq applies all operations of a batch or none of them
+func (c *restBatchClient) Apply(ctx context.Context, req batchRequest) (*batchResponse, error) {
+	res := &batchResponse{}
+
+	err := receive(ctx, c.client.restClient().Post("v2/batch").BodyJSON(req), res, "apply batch")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
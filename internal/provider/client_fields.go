@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+// fieldsClient extends the upstream FieldsClient with the methods to manage fields
+type fieldsClient interface {
+	ardoq.FieldsClient
+
+	Create(ctx context.Context, req fieldRequest) (*ardoq.Field, error)
+	Update(ctx context.Context, id string, req fieldRequest) (*ardoq.Field, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// fieldRequest is the payload for creating and updating a field
+// URL: PATCH/POST /api/field
+type fieldRequest struct {
+	Name          interface{} `json:"name,omitempty"`
+	Label         interface{} `json:"label,omitempty"`
+	Type          interface{} `json:"type,omitempty"`
+	Model         interface{} `json:"model,omitempty"`
+	Description   interface{} `json:"description,omitempty"`
+	DefaultValue  interface{} `json:"defaultValue,omitempty"`
+	Global        interface{} `json:"global,omitempty"`
+	GlobalRef     interface{} `json:"globalref,omitempty"`
+	ComponentType interface{} `json:"componentType,omitempty"`
+	ReferenceType interface{} `json:"referenceType,omitempty"`
+	Options       interface{} `json:"options,omitempty"`
+}
+
+type restFieldsClient struct {
+	ardoq.FieldsClient
+	client *apiClient
+}
+
+var _ fieldsClient = &restFieldsClient{}
+
+// Fields returns a fieldsClient for reading and managing Fields in Ardoq
+func (c *apiClient) Fields() fieldsClient {
+	return &restFieldsClient{FieldsClient: c.Client.Fields(), client: c}
+}
+
+// Create creates a field
+func (c *restFieldsClient) Create(ctx context.Context, req fieldRequest) (*ardoq.Field, error) {
+	res := &ardoq.Field{}
+
+	err := receive(ctx, c.client.restClient().Post("field").BodyJSON(req), res, "create field")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Update updates a field
+func (c *restFieldsClient) Update(ctx context.Context, id string, req fieldRequest) (*ardoq.Field, error) {
+	res := &ardoq.Field{}
+
+	err := receive(ctx, c.client.restClient().Patch("field/"+id).BodyJSON(req), res, "update field")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Delete deletes a field
+func (c *restFieldsClient) Delete(ctx context.Context, id string) error {
+	return receive(ctx, c.client.restClient().Delete("field/"+id), nil, "delete field")
+}
+
+// fieldOptions returns the options of a "List" or "SelectMultipleList" field,
+// the upstream ardoq.Field has no attribute for them so they end up in Fields
+func fieldOptions(field *ardoq.Field) []string {
+	var result []string
+
+	if options, ok := field.Fields["options"].([]interface{}); ok {
+		for _, option := range options {
+			if s, ok := option.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+
+	return result
+}
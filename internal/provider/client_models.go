@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+// modelsClient extends the upstream ModelsClient with the methods to manage models
+type modelsClient interface {
+	ardoq.ModelsClient
+
+	Create(ctx context.Context, req modelDefinition) (*modelDefinition, error)
+	ReadDefinition(ctx context.Context, id string) (*modelDefinition, error)
+	Update(ctx context.Context, id string, req modelDefinition) (*modelDefinition, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// modelDefinition is the part of the model JSON that defines the metamodel,
+// it is used both as payload and as response for the ardoq_model resource
+// URL: PATCH/POST /api/model
+type modelDefinition struct {
+	ID             string                        `json:"-" mapstructure:"_id"`
+	Name           string                        `json:"name" mapstructure:"name"`
+	Description    string                        `json:"description" mapstructure:"description"`
+	Root           map[string]modelComponentType `json:"root" mapstructure:"root"`
+	ReferenceTypes map[string]modelReferenceType `json:"referenceTypes" mapstructure:"referenceTypes"`
+}
+
+// modelComponentType is a component type, component types form a tree through their children
+type modelComponentType struct {
+	ID       string                        `json:"id" mapstructure:"id"`
+	Name     string                        `json:"name" mapstructure:"name"`
+	Color    string                        `json:"color,omitempty" mapstructure:"color"`
+	Icon     string                        `json:"icon,omitempty" mapstructure:"icon"`
+	Image    string                        `json:"image,omitempty" mapstructure:"image"`
+	Shape    string                        `json:"shape,omitempty" mapstructure:"shape"`
+	Index    int                           `json:"index" mapstructure:"index"`
+	Level    int                           `json:"level" mapstructure:"level"`
+	Children map[string]modelComponentType `json:"children" mapstructure:"children"`
+}
+
+// modelReferenceType is a reference type, unlike ardoq.MdoelReferenceTypes it holds all the attributes
+type modelReferenceType struct {
+	ID            int    `json:"id" mapstructure:"id"`
+	Name          string `json:"name" mapstructure:"name"`
+	Color         string `json:"color,omitempty" mapstructure:"color"`
+	Line          string `json:"line,omitempty" mapstructure:"line"`
+	LineBeginning string `json:"lineBeginning,omitempty" mapstructure:"lineBeginning"`
+	LineEnding    string `json:"lineEnding,omitempty" mapstructure:"lineEnding"`
+	ReturnName    string `json:"returnName,omitempty" mapstructure:"returnName"`
+}
+
+type restModelsClient struct {
+	ardoq.ModelsClient
+	client *apiClient
+}
+
+var _ modelsClient = &restModelsClient{}
+
+// Models returns a modelsClient for reading and managing Models in Ardoq
+func (c *apiClient) Models() modelsClient {
+	return &restModelsClient{ModelsClient: c.Client.Models(), client: c}
+}
+
+// Create creates a model
+func (c *restModelsClient) Create(ctx context.Context, req modelDefinition) (*modelDefinition, error) {
+	res := &modelDefinition{}
+
+	err := receive(ctx, c.client.restClient().Post("model").BodyJSON(req), res, "create model")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ReadDefinition retrieves the metamodel of a model by its ID
+func (c *restModelsClient) ReadDefinition(ctx context.Context, id string) (*modelDefinition, error) {
+	res := &modelDefinition{}
+
+	err := receive(ctx, c.client.restClient().Get("model/"+id), res, "get model")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Update updates a model
+func (c *restModelsClient) Update(ctx context.Context, id string, req modelDefinition) (*modelDefinition, error) {
+	res := &modelDefinition{}
+
+	err := receive(ctx, c.client.restClient().Patch("model/"+id).BodyJSON(req), res, "update model")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Delete deletes a model
+func (c *restModelsClient) Delete(ctx context.Context, id string) error {
+	return receive(ctx, c.client.restClient().Delete("model/"+id), nil, "delete model")
+}
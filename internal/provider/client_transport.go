@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// retryTransport retries requests which failed because Ardoq is rate limiting or temporarily unavailable.
+// A 429 means the request was not processed, so it is retried for every method, the
+// 502, 503 and 504 responses are only retried for idempotent methods
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	minWait    time.Duration
+	maxWait    time.Duration
+}
+
+func newRetryTransport(next http.RoundTripper, maxRetries int, minWait, maxWait time.Duration) *retryTransport {
+	return &retryTransport{
+		next:       next,
+		maxRetries: maxRetries,
+		minWait:    minWait,
+		maxWait:    maxWait,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries || !retryable(req.Method, resp.StatusCode) {
+			return resp, err
+		}
+
+		// the body was consumed by the previous attempt, it can only be sent again if it can be recreated
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		wait := t.wait(attempt, resp)
+		log.Printf("[DEBUG] %s %s returned %d, retry %d of %d in %s", req.Method, req.URL.Path, resp.StatusCode, attempt+1, t.maxRetries, wait)
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// wait returns how long to wait before the next attempt, the Retry-After header of the response
+// is used when it is set, otherwise the wait time doubles every attempt. Both are limited to maxWait
+func (t *retryTransport) wait(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		if wait > t.maxWait {
+			wait = t.maxWait
+		}
+		return wait
+	}
+
+	wait := t.minWait
+	for i := 0; i < attempt && wait < t.maxWait; i++ {
+		wait *= 2
+	}
+	if wait > t.maxWait {
+		wait = t.maxWait
+	}
+
+	return wait
+}
+
+func retryable(method string, code int) bool {
+	switch code {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfter parses a Retry-After header, which is either a number of seconds or a date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// throttleTransport limits the number of requests per second and the number of requests in flight,
+// one throttleTransport is shared by all resources and data sources of a provider configuration
+type throttleTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+// newThrottleTransport returns a throttleTransport, a requestsPerSecond or maxConcurrent of 0 means no limit
+func newThrottleTransport(next http.RoundTripper, requestsPerSecond float64, maxConcurrent int) *throttleTransport {
+	t := &throttleTransport{next: next}
+
+	if requestsPerSecond > 0 {
+		// a burst of 1 spreads the requests evenly, instead of sending them all at once when idle
+		t.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), 1)
+	}
+
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	return t
+}
+
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	release := func() {}
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-t.slots }) }
+	}
+
+	if t.limiter != nil {
+		if err := t.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// the request is in flight until its response is read
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+// releaseOnClose calls release when the body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	defer r.release()
+
+	return r.ReadCloser.Close()
+}
+
+// defaultClientTransports routes the requests sent with http.DefaultClient to the transport of the
+// provider configuration they belong to. The ardoq-client-go client always sends its requests with
+// http.DefaultClient and has no option to use another *http.Client, so this is the only way to retry
+// and throttle its requests as well. Its requests only tell the configurations apart by base URI,
+// API key and org, configurations which have all three in common share the transport configured last
+var defaultClientTransports = &transportRouter{transports: make(map[transportKey]http.RoundTripper)}
+
+// transportKey identifies the provider configuration a request belongs to
+type transportKey struct {
+	baseURI       string
+	authorization string
+	org           string
+}
+
+type transportRouter struct {
+	install    sync.Once
+	mu         sync.RWMutex
+	transports map[transportKey]http.RoundTripper
+	next       http.RoundTripper
+}
+
+// register sends the requests for the base URI, API key and org through the transport,
+// the first call installs the router on http.DefaultClient
+func (r *transportRouter) register(baseURI, apiKey, org string, transport http.RoundTripper) {
+	r.install.Do(func() {
+		r.next = http.DefaultClient.Transport
+		if r.next == nil {
+			r.next = http.DefaultTransport
+		}
+		http.DefaultClient.Transport = r
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the same Authorization header as the upstream client sends
+	r.transports[transportKey{baseURI: baseURI, authorization: fmt.Sprintf("Token token=%s", apiKey), org: org}] = transport
+}
+
+func (r *transportRouter) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorization := req.Header.Get("Authorization")
+	org := req.URL.Query().Get("org")
+	url := req.URL.String()
+
+	r.mu.RLock()
+	var transport http.RoundTripper
+	match := ""
+	for key, t := range r.transports {
+		if key.authorization != authorization || key.org != org {
+			continue
+		}
+		// the longest base URI wins, when configurations share a host
+		if strings.HasPrefix(url, key.baseURI) && len(key.baseURI) > len(match) {
+			match, transport = key.baseURI, t
+		}
+	}
+	r.mu.RUnlock()
+
+	if transport == nil {
+		transport = r.next
+	}
+
+	return transport.RoundTrip(req)
+}
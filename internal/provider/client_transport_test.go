@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport_rateLimited(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	// a create is retried too, Ardoq didn't process the request
+	f.failWithHeader("POST", "component", http.StatusTooManyRequests, 2, http.Header{"Retry-After": []string{"0"}})
+
+	state := testApplyResource(t, resourceArdoqComponent(), nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+	}, meta)
+
+	if count := f.requestCount("POST", "component"); count != 3 {
+		t.Errorf("%d create requests, want 3", count)
+	}
+	// the body is sent again with every retry
+	if name := f.get("component", state.ID)["name"]; name != "component" {
+		t.Errorf("name = %v, want component", name)
+	}
+}
+
+func TestRetryTransport_unavailable(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponent()
+	config := map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+	}
+
+	// a create is not idempotent, it could have been processed
+	f.fail("POST", "component", http.StatusServiceUnavailable, 1)
+	if _, err := testApplyResourceErr(r, nil, config, meta); err == nil {
+		t.Fatal("create succeeded, want the 503 to be returned")
+	}
+	if count := f.requestCount("POST", "component"); count != 1 {
+		t.Errorf("%d create requests, want 1", count)
+	}
+
+	state := testApplyResource(t, r, nil, config, meta)
+
+	// reading the component goes through the ardoq-client-go client
+	f.fail("GET", "component/"+state.ID, http.StatusBadGateway, 1)
+	f.fail("GET", "component/"+state.ID, http.StatusGatewayTimeout, 1)
+	if state := testRefreshResource(t, r, state, meta); state == nil {
+		t.Fatal("component removed from the state")
+	}
+	if count := f.requestCount("GET", "component/"+state.ID); count != 4 {
+		t.Errorf("%d read requests, want 4", count)
+	}
+}
+
+func TestRetryTransport_maxRetries(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMetaWithConfig(t, f, map[string]interface{}{"max_retries": 2})
+	workspace := testFakeWorkspace(f)
+
+	f.fail("GET", "workspace/"+workspace, http.StatusTooManyRequests, 10)
+
+	if _, err := workspaceModel(context.Background(), meta.(*apiClient), workspace); err == nil {
+		t.Fatal("reading the workspace succeeded, want the 429 to be returned")
+	}
+	if count := f.requestCount("GET", "workspace/"+workspace); count != 3 {
+		t.Errorf("%d requests, want 3", count)
+	}
+}
+
+func TestRetryTransport_canceled(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMetaWithConfig(t, f, map[string]interface{}{"retry_min_wait": 60, "retry_max_wait": 60})
+	testFakeWorkspace(f)
+
+	f.fail("GET", "model/model1", http.StatusTooManyRequests, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := meta.(*apiClient).Models().ReadDefinition(ctx, "model1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want the request to be canceled while waiting for the retry", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("request took %s, want it to stop waiting at the deadline", elapsed)
+	}
+	if count := f.requestCount("GET", "model/model1"); count != 1 {
+		t.Errorf("%d requests, want 1", count)
+	}
+}
+
+func TestTransportRouter_aliases(t *testing.T) {
+	f := newFakeArdoq(t)
+	// two configurations of the same Ardoq, i.e. aliases with different API keys
+	noRetries := testFakeMetaWithConfig(t, f, map[string]interface{}{"apikey": "first", "max_retries": 0})
+	retries := testFakeMetaWithConfig(t, f, map[string]interface{}{"apikey": "second", "max_retries": 2})
+	workspace := testFakeWorkspace(f)
+	component := f.put("component", map[string]interface{}{"name": "component", "rootWorkspace": workspace})
+
+	// reading a component goes through the ardoq-client-go client, which uses http.DefaultClient
+	f.fail("GET", "component/"+component, http.StatusTooManyRequests, 10)
+
+	if _, err := noRetries.(*apiClient).Components().Read(context.Background(), component); err == nil {
+		t.Fatal("reading the component succeeded, want the 429 to be returned")
+	}
+	if count := f.requestCount("GET", "component/"+component); count != 1 {
+		t.Errorf("%d requests, want 1 without retries", count)
+	}
+
+	if _, err := retries.(*apiClient).Components().Read(context.Background(), component); err == nil {
+		t.Fatal("reading the component succeeded, want the 429 to be returned")
+	}
+	if count := f.requestCount("GET", "component/"+component); count != 4 {
+		t.Errorf("%d requests, want 1 without retries and 3 with 2 retries", count)
+	}
+}
+
+func TestRetryTransportWait(t *testing.T) {
+	transport := newRetryTransport(nil, 10, time.Second, 5*time.Second)
+
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := transport.wait(attempt, &http.Response{Header: http.Header{}}); got != want {
+			t.Errorf("wait(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	if got := transport.wait(0, resp); got != 3*time.Second {
+		t.Errorf("wait with Retry-After = %s, want 3s", got)
+	}
+
+	// Ardoq can't make the provider wait longer than maxWait
+	resp.Header.Set("Retry-After", "3600")
+	if got := transport.wait(0, resp); got != 5*time.Second {
+		t.Errorf("wait with Retry-After of an hour = %s, want 5s", got)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if got := transport.wait(3, resp); got != 0 {
+		t.Errorf("wait with Retry-After in the past = %s, want 0s", got)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	cases := []struct {
+		method string
+		code   int
+		want   bool
+	}{
+		{http.MethodPost, http.StatusTooManyRequests, true},
+		{http.MethodPatch, http.StatusTooManyRequests, true},
+		{http.MethodGet, http.StatusServiceUnavailable, true},
+		{http.MethodDelete, http.StatusBadGateway, true},
+		{http.MethodPut, http.StatusGatewayTimeout, true},
+		{http.MethodPost, http.StatusServiceUnavailable, false},
+		{http.MethodPatch, http.StatusGatewayTimeout, false},
+		{http.MethodGet, http.StatusInternalServerError, false},
+		{http.MethodGet, http.StatusNotFound, false},
+	}
+
+	for _, c := range cases {
+		if got := retryable(c.method, c.code); got != c.want {
+			t.Errorf("retryable(%s, %d) = %t, want %t", c.method, c.code, got, c.want)
+		}
+	}
+}
+
+// countingTransport returns an empty response after a delay, and keeps track of the requests in flight
+type countingTransport struct {
+	delay time.Duration
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.inFlight++
+	if t.inFlight > t.maxInFlight {
+		t.maxInFlight = t.inFlight
+	}
+	t.mu.Unlock()
+
+	time.Sleep(t.delay)
+
+	t.mu.Lock()
+	t.inFlight--
+	t.mu.Unlock()
+
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func testRoundTrips(t *testing.T, transport http.RoundTripper, count int) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, _ := http.NewRequest(http.MethodGet, "http://ardoq.test/api/component", nil)
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestThrottleTransport_maxConcurrent(t *testing.T) {
+	next := &countingTransport{delay: 10 * time.Millisecond}
+
+	testRoundTrips(t, newThrottleTransport(next, 0, 2), 10)
+
+	if next.maxInFlight != 2 {
+		t.Errorf("%d requests in flight, want 2", next.maxInFlight)
+	}
+}
+
+func TestThrottleTransport_requestsPerSecond(t *testing.T) {
+	next := &countingTransport{}
+
+	start := time.Now()
+	testRoundTrips(t, newThrottleTransport(next, 20, 0), 5)
+
+	// the first request is sent at once, the next 4 every 50ms
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("5 requests took %s, want at least 200ms at 20 requests per second", elapsed)
+	}
+}
+
+func TestThrottleTransport_noLimits(t *testing.T) {
+	next := &countingTransport{delay: 10 * time.Millisecond}
+
+	testRoundTrips(t, newThrottleTransport(next, 0, 0), 10)
+
+	if next.maxInFlight < 2 {
+		t.Errorf("%d requests in flight, want them sent at the same time", next.maxInFlight)
+	}
+}
+
+func TestThrottleTransport_canceled(t *testing.T) {
+	throttle := newThrottleTransport(&countingTransport{}, 0, 1)
+
+	// the only slot is taken by a request whose response is not read yet
+	req, _ := http.NewRequest(http.MethodGet, "http://ardoq.test/api/component", nil)
+	resp, err := throttle.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := throttle.RoundTrip(req.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Errorf("error = %v, want the request to wait for a slot until the deadline", err)
+	}
+
+	resp.Body.Close()
+	if _, err := throttle.RoundTrip(req); err != nil {
+		t.Errorf("error = %v, want the slot to be released", err)
+	}
+}
+
+func TestThrottleTransport_provider(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMetaWithConfig(t, f, map[string]interface{}{
+		"requests_per_second":     100.0,
+		"max_concurrent_requests": 1,
+	})
+	testFakeWorkspace(f)
+
+	// requests of the ardoq-client-go client and the provider's own requests share the limits,
+	// with one request at a time this hangs if a response doesn't release its slot
+	state := testReadDataSource(t, dataSourceArdoqModel(), map[string]interface{}{"id": "model1"}, meta)
+	testCheckAttributes(t, state, map[string]string{"name": "Test"})
+
+	if _, err := meta.(*apiClient).Models().ReadDefinition(context.Background(), "model1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+// workspacesClient extends the upstream WorkspacesClient with the methods to manage workspaces
+type workspacesClient interface {
+	ardoq.WorkspacesClient
+
+	Create(ctx context.Context, req workspaceRequest) (*ardoq.Workspace, error)
+	Update(ctx context.Context, id string, req workspaceRequest) (*ardoq.Workspace, error)
+	Delete(ctx context.Context, id string) error
+	References(ctx context.Context, id string) (*[]ardoq.Reference, error)
+}
+
+// workspaceRequest is the payload for creating and updating a workspace
+// Fields map has json tag "-" so that it doesn't get marshalled into JSON
+// the fields are being merged into the body by the ardoqBodyProvider
+// URL: PATCH/POST /api/workspace
+type workspaceRequest struct {
+	Name              interface{}            `json:"name,omitempty"`
+	Description       interface{}            `json:"description,omitempty"`
+	ComponentModel    interface{}            `json:"componentModel,omitempty"`
+	ComponentTemplate interface{}            `json:"componentTemplate,omitempty"`
+	Fields            map[string]interface{} `json:"-"`
+}
+
+type restWorkspacesClient struct {
+	ardoq.WorkspacesClient
+	client *apiClient
+}
+
+var _ workspacesClient = &restWorkspacesClient{}
+
+// Workspaces returns a workspacesClient for reading and managing Workspaces in Ardoq
+func (c *apiClient) Workspaces() workspacesClient {
+	return &restWorkspacesClient{WorkspacesClient: c.Client.Workspaces(), client: c}
+}
+
+// Create creates a workspace
+func (c *restWorkspacesClient) Create(ctx context.Context, req workspaceRequest) (*ardoq.Workspace, error) {
+	res := &ardoq.Workspace{}
+
+	err := receive(ctx, c.client.restClient().Post("workspace").
+		BodyProvider(ardoqBodyProvider{request: req, fields: req.Fields}), res, "create workspace")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Update updates a workspace
+func (c *restWorkspacesClient) Update(ctx context.Context, id string, req workspaceRequest) (*ardoq.Workspace, error) {
+	res := &ardoq.Workspace{}
+
+	err := receive(ctx, c.client.restClient().Patch("workspace/"+id).
+		BodyProvider(ardoqBodyProvider{request: req, fields: req.Fields}), res, "update workspace")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Delete deletes a workspace
+func (c *restWorkspacesClient) Delete(ctx context.Context, id string) error {
+	return receive(ctx, c.client.restClient().Delete("workspace/"+id), nil, "delete workspace")
+}
+
+// References retrieves the references of a workspace, the references with a source component in the workspace
+func (c *restWorkspacesClient) References(ctx context.Context, id string) (*[]ardoq.Reference, error) {
+	res := &[]ardoq.Reference{}
+
+	err := receive(ctx, c.client.restClient().Get("workspace/"+id+"/reference"), res, "get references of workspace")
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
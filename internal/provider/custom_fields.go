@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceGetter is implemented by both *schema.ResourceData and *schema.ResourceDiff
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+// customFieldsSchema is the "fields" attribute of components and references,
+// fieldsJSONSchema is the typed alternative, only one of both can be used
+func customFieldsSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "All custom fields from the model end up here",
+		Type:        schema.TypeMap,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+		Optional:      true,
+		ConflictsWith: []string{"fields_json"},
+	}
+}
+
+func fieldsJSONSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "All custom fields from the model as a JSON encoded object, use `jsonencode()` so numbers, booleans, " +
+			"lists and nulls keep their type. Setting a field to `null` clears it. Conflicts with `fields`",
+		Type:             schema.TypeString,
+		Optional:         true,
+		ConflictsWith:    []string{"fields"},
+		ValidateFunc:     validateFieldsJSON,
+		DiffSuppressFunc: suppressEquivalentFieldsJSON,
+	}
+}
+
+// expandCustomFields returns the custom fields from either "fields" or "fields_json"
+func expandCustomFields(d resourceGetter) (map[string]interface{}, error) {
+	if v := d.Get("fields_json").(string); v != "" {
+		return decodeFieldsJSON(v)
+	}
+
+	fields := make(map[string]interface{})
+	for k, v := range d.Get("fields").(map[string]interface{}) {
+		fields[k] = v.(string)
+	}
+
+	return fields, nil
+}
+
+// expandChangedCustomFields returns the custom fields for an update. Ardoq only updates the fields
+// it is sent, so the fields removed from the configuration are sent as null to clear them. The state
+// can have other attributes Ardoq returns as well, i.e. the icon, those are never sent as null
+func expandChangedCustomFields(ctx context.Context, c *apiClient, d *schema.ResourceData) (map[string]interface{}, error) {
+	fields, err := expandCustomFields(d)
+	if err != nil {
+		return nil, err
+	}
+
+	oldFields, _ := d.GetChange("fields")
+	oldFieldsJSON, _ := d.GetChange("fields_json")
+
+	old := oldFields.(map[string]interface{})
+	if v := oldFieldsJSON.(string); v != "" {
+		// the old value was valid when it was applied, if not there is nothing to clear
+		if decoded, err := decodeFieldsJSON(v); err == nil {
+			old = decoded
+		}
+	}
+
+	removed := make(map[string]interface{})
+	for k, v := range old {
+		if _, ok := fields[k]; !ok {
+			removed[k] = v
+		}
+	}
+	if len(removed) == 0 {
+		return fields, nil
+	}
+
+	// the fields of the model are only read when fields are removed
+	rootWorkspace := d.Get("root_workspace").(string)
+	workspace, err := c.Workspaces().Get(ctx, rootWorkspace)
+	if err != nil {
+		return nil, fmt.Errorf("could not read workspace %s to clear removed fields: %w", rootWorkspace, err)
+	}
+	modelFields, err := modelFields(ctx, c, workspace.ComponentModel)
+	if err != nil {
+		return nil, fmt.Errorf("could not read fields to clear removed fields: %w", err)
+	}
+
+	for k := range removed {
+		if _, ok := modelFields[k]; !ok {
+			log.Printf("[DEBUG] %s is not a custom field of model %s, it is not cleared", k, workspace.ComponentModel)
+			delete(removed, k)
+		}
+	}
+
+	return nullRemovedFields(fields, removed), nil
+}
+
+// nullRemovedFields adds the keys of old which are not in fields to fields, with a nil value
+func nullRemovedFields(fields, old map[string]interface{}) map[string]interface{} {
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+
+	for k := range old {
+		if _, ok := fields[k]; !ok {
+			fields[k] = nil
+		}
+	}
+
+	return fields
+}
+
+// setCustomFields sets the custom fields read from Ardoq on the attribute that is used in the configuration,
+// the other one is cleared. "fields" is the default, i.e. after an import
+func setCustomFields(d *schema.ResourceData, fields map[string]interface{}) error {
+	if d.Get("fields_json").(string) == "" {
+		if err := d.Set("fields_json", ""); err != nil {
+			return err
+		}
+		return d.Set("fields", convertFields(fields))
+	}
+
+	fieldsJSON, err := encodeFieldsJSON(fields)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("fields_json", fieldsJSON); err != nil {
+		return err
+	}
+	return d.Set("fields", nil)
+}
+
+// decodeFieldsJSON decodes a JSON object, numbers are kept as json.Number so they are sent to Ardoq unchanged
+func decodeFieldsJSON(s string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+
+	dec := json.NewDecoder(bytes.NewBufferString(s))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("fields_json must be a JSON object: %w", err)
+	}
+
+	return fields, nil
+}
+
+// encodeFieldsJSON encodes the custom fields as a JSON object, empty fields are left out
+func encodeFieldsJSON(fields map[string]interface{}) (string, error) {
+	result := make(map[string]interface{})
+	for k, v := range fields {
+		if v != nil {
+			result[k] = v
+		}
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func validateFieldsJSON(i interface{}, k string) (warnings []string, errors []error) {
+	if _, err := decodeFieldsJSON(i.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %w", k, err))
+	}
+
+	return warnings, errors
+}
+
+// suppressEquivalentFieldsJSON ignores formatting and fields set to null, Ardoq doesn't return empty fields
+func suppressEquivalentFieldsJSON(k, old, new string, d *schema.ResourceData) bool {
+	normalize := func(s string) map[string]interface{} {
+		fields := make(map[string]interface{})
+		if json.Unmarshal([]byte(s), &fields) != nil {
+			return nil
+		}
+		for k, v := range fields {
+			if v == nil {
+				delete(fields, k)
+			}
+		}
+		return fields
+	}
+
+	oldFields, newFields := normalize(old), normalize(new)
+	if oldFields == nil || newFields == nil {
+		return false
+	}
+
+	return reflect.DeepEqual(oldFields, newFields)
+}
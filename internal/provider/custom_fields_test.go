@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandCustomFields_json(t *testing.T) {
+	t.Parallel()
+
+	d := schema.TestResourceDataRaw(t, resourceArdoqComponent().Schema, map[string]interface{}{
+		"name":           "component",
+		"root_workspace": "workspace",
+		"fields_json":    `{"cost": 12345678901234567890, "active": true, "tags": ["a", "b"], "owner": null}`,
+	})
+
+	fields, err := expandCustomFields(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fields["cost"] != json.Number("12345678901234567890") {
+		t.Errorf("expected the number to be unchanged, got %#v", fields["cost"])
+	}
+	if fields["active"] != true {
+		t.Errorf("expected a boolean, got %#v", fields["active"])
+	}
+	if tags, ok := fields["tags"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("expected a list, got %#v", fields["tags"])
+	}
+	if v, ok := fields["owner"]; !ok || v != nil {
+		t.Errorf("expected owner to be null, got %#v", fields["owner"])
+	}
+}
+
+func TestExpandCustomFields_map(t *testing.T) {
+	t.Parallel()
+
+	d := schema.TestResourceDataRaw(t, resourceArdoqComponent().Schema, map[string]interface{}{
+		"name":           "component",
+		"root_workspace": "workspace",
+		"fields":         map[string]interface{}{"owner": "me"},
+	})
+
+	fields, err := expandCustomFields(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fields) != 1 || fields["owner"] != "me" {
+		t.Errorf("unexpected fields %#v", fields)
+	}
+}
+
+func TestSuppressEquivalentFieldsJSON(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		old, new string
+		suppress bool
+	}{
+		{`{"a":1,"b":[true]}`, `{"b": [true], "a": 1.0}`, true},
+		{`{"a":1}`, `{"a":1,"b":null}`, true},
+		{`{"a":1}`, `{"a":"1"}`, false},
+		{`{"a":1}`, `{"a":1,"b":2}`, false},
+		{``, `{"a":1}`, false},
+	}
+
+	for _, tc := range cases {
+		if got := suppressEquivalentFieldsJSON("fields_json", tc.old, tc.new, nil); got != tc.suppress {
+			t.Errorf("%s -> %s: expected suppress %t, got %t", tc.old, tc.new, tc.suppress, got)
+		}
+	}
+}
+
+func TestEncodeFieldsJSON(t *testing.T) {
+	t.Parallel()
+
+	s, err := encodeFieldsJSON(map[string]interface{}{"b": 2.5, "a": []interface{}{"x"}, "c": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"a":["x"],"b":2.5}`; s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+}
@@ -3,20 +3,46 @@ package provider
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"time"
+	"regexp"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	ardoq "github.com/mories76/ardoq-client-go/pkg"
 )
 
 func dataSourceArdoqComponent() *schema.Resource {
 	dsSchema := datasourceSchemaFromResourceSchema(resourceArdoqComponent().Schema)
-	addRequiredFieldsToSchema(dsSchema, "root_workspace", "name")
+	dsSchema["root_workspace"].Optional = true
+	dsSchema["root_workspace"].Description = "Id of the workspace the component belongs to, required to look up a component by `name`, `path` or `field`"
+	dsSchema["path"] = &schema.Schema{
+		Description: "Names of the component and its ancestors separated by `/`, starting at the top level of the workspace, i.e. `Platform/Payments/Checkout API`",
+		Type:        schema.TypeString,
+	}
+	dsSchema["field"] = &schema.Schema{
+		Description: "Custom field with a value which identifies the component",
+		Type:        schema.TypeList,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Description: "Name of the custom field",
+					Type:        schema.TypeString,
+					Required:    true,
+				},
+				"value": {
+					Description: "Value of the custom field",
+					Type:        schema.TypeString,
+					Required:    true,
+				},
+			},
+		},
+	}
+	addExactlyOneOfFieldsToSchema(dsSchema, "id", "name", "path", "field")
 
 	return &schema.Resource{
-		Description: "`ardoq_component` data source can be used to retrieve information for a component by name and workspace.",
+		Description: "`ardoq_component` data source can be used to retrieve information for a component by id, by name, by path or by the value of a custom field.",
 		ReadContext: dataSourceArdoqComponentRead,
 		Schema:      dsSchema,
 	}
@@ -26,7 +52,7 @@ func dataSourceArdoqComponents() *schema.Resource {
 	dsSchema := datasourceSchemaFromResourceSchema(resourceArdoqComponent().Schema)
 
 	return &schema.Resource{
-		Description: "`ardoq_components` data source can be used to retrieve all components from a specific workspace.",
+		Description: "`ardoq_components` data source can be used to retrieve the components from a specific workspace, optionally filtered by name, type, parent or custom fields.",
 		ReadContext: dataSourceArdoqComponentsRead,
 		Schema: map[string]*schema.Schema{
 			"root_workspace": {
@@ -34,6 +60,47 @@ func dataSourceArdoqComponents() *schema.Resource {
 				Type:        schema.TypeString,
 				Required:    true,
 			},
+			"name": {
+				Description: "Only return components with this name",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"name_prefix": {
+				Description: "Only return components with a name starting with this prefix",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"name_regex": {
+				Description:  "Only return components with a name matching this regular expression",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"type_id": {
+				Description:   "Only return components of the type with this id, conflicts with `type_name`",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"type_name"},
+			},
+			"type_name": {
+				Description:   "Only return components of the type with this name, conflicts with `type_id`",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"type_id"},
+			},
+			"parent": {
+				Description: "Only return the children of the component with this id",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"fields": {
+				Description: "Only return components with these values for their custom fields",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"components": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -48,24 +115,63 @@ func dataSourceArdoqComponents() *schema.Resource {
 func dataSourceArdoqComponentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
-	componentName := d.Get("name").(string)
-	rootWorkspace := d.Get("root_workspace").(string)
+	c := m.(*apiClient)
 
-	components, err := c.Components().Search(ctx, &ardoq.ComponentSearchQuery{Name: componentName, Workspace: rootWorkspace})
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	// TODO: check other datasource/resources for error handling
-	if len(*components) != 1 { // check if components result is 1, if 0 then no result was found, if more then 1 was found, the query was not specific enough
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  fmt.Sprintf("%d components found, ardoq_component should return 1", len(*components)),
+	var component *ardoq.Component
+	if id, ok := d.GetOk("id"); ok {
+		var err error
+		if component, err = c.Components().Read(ctx, id.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	} else {
+		rootWorkspace, ok := d.GetOk("root_workspace")
+		if !ok {
+			return diag.Errorf("root_workspace is required to look up a component by name, path or field")
+		}
+
+		// only the name is searched by Ardoq, a path or field is looked up in all components of the workspace
+		components, err := c.Components().Search(ctx, &ardoq.ComponentSearchQuery{
+			Name:      d.Get("name").(string),
+			Workspace: rootWorkspace.(string),
 		})
-		return diags
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		var matches []ardoq.Component
+		switch {
+		case d.Get("path").(string) != "":
+			matches = componentsByPath(*components, strings.Split(d.Get("path").(string), "/"))
+		case len(d.Get("field").([]interface{})) > 0:
+			field := d.Get("field").([]interface{})[0].(map[string]interface{})
+			for _, candidate := range *components {
+				if value, ok := convertFields(candidate.Fields)[field["name"].(string)]; ok && value == field["value"].(string) {
+					matches = append(matches, candidate)
+				}
+			}
+		default:
+			matches = *components
+		}
+
+		// check if components result is 1, if 0 then no result was found, if more then 1 was found, the query was not specific enough
+		if len(matches) != 1 {
+			diagnostic := diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("%d components found, ardoq_component should return 1", len(matches)),
+			}
+			if len(matches) > 1 {
+				ids := make([]string, 0, len(matches))
+				for _, match := range matches {
+					ids = append(ids, match.ID)
+				}
+				diagnostic.Detail = fmt.Sprintf("The components found are %q, use the id, path or a field to select one of them", ids)
+			}
+			return append(diags, diagnostic)
+		}
+		component = &matches[0]
 	}
 
-	cmp := flattenComponent(&(*components)[0])
+	cmp := flattenComponent(component)
 
 	// loop through map "cmp", and update the schema for each key value pair
 	for key, val := range cmp {
@@ -74,37 +180,139 @@ func dataSourceArdoqComponentRead(ctx context.Context, d *schema.ResourceData, m
 		}
 	}
 
-	d.SetId((*components)[0].ID)
+	d.SetId(component.ID)
 
 	return diags
 }
 
+// componentsByPath returns the components with the path, names is the path split in names starting at the top level
+func componentsByPath(components []ardoq.Component, names []string) []ardoq.Component {
+	// the top level components have no parent
+	parents := map[string]bool{"": true}
+
+	var matches []ardoq.Component
+	for _, name := range names {
+		matches = nil
+		for _, component := range components {
+			parent, _ := component.Parent.(string)
+			if component.Name == name && parents[parent] {
+				matches = append(matches, component)
+			}
+		}
+
+		parents = make(map[string]bool, len(matches))
+		for _, match := range matches {
+			parents[match.ID] = true
+		}
+	}
+
+	return matches
+}
+
 func dataSourceArdoqComponentsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 	rootWorkspace := d.Get("root_workspace").(string)
 
+	// the search of Ardoq only filters on workspace and name, the other filters are applied to the result
 	var qry = &ardoq.ComponentSearchQuery{
 		Workspace: rootWorkspace,
+		Name:      d.Get("name").(string),
 	}
 	components, err := c.Components().Search(ctx, qry)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	if err := d.Set("components", flattenComponents(components)); err != nil {
+	filter := expandComponentFilter(d)
+	matches := make([]ardoq.Component, 0, len(*components))
+	for _, component := range *components {
+		if filter.matches(&component) {
+			matches = append(matches, component)
+		}
+	}
+
+	flatComponents := flattenComponents(&matches)
+	if err := d.Set("components", flatComponents); err != nil {
 		return diag.FromErr(err)
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(dataSourceHashID(d, flatComponents, "root_workspace", "name", "name_prefix", "name_regex", "type_id", "type_name", "parent", "fields"))
 
 	return diags
 }
 
+// componentFilter holds the filters of the ardoq_components data source, empty filters match every component
+type componentFilter struct {
+	name       string
+	namePrefix string
+	nameRegex  *regexp.Regexp
+	typeID     string
+	typeName   string
+	parent     string
+	fields     map[string]string
+}
+
+func expandComponentFilter(d *schema.ResourceData) componentFilter {
+	filter := componentFilter{
+		name:       d.Get("name").(string),
+		namePrefix: d.Get("name_prefix").(string),
+		typeID:     d.Get("type_id").(string),
+		typeName:   d.Get("type_name").(string),
+		parent:     d.Get("parent").(string),
+		fields:     make(map[string]string),
+	}
+
+	// the regular expression is validated during plan
+	if v, ok := d.GetOk("name_regex"); ok {
+		filter.nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	for k, v := range d.Get("fields").(map[string]interface{}) {
+		filter.fields[k] = v.(string)
+	}
+
+	return filter
+}
+
+func (f componentFilter) matches(component *ardoq.Component) bool {
+	if f.name != "" && component.Name != f.name {
+		return false
+	}
+	if !strings.HasPrefix(component.Name, f.namePrefix) {
+		return false
+	}
+	if f.nameRegex != nil && !f.nameRegex.MatchString(component.Name) {
+		return false
+	}
+	if f.typeID != "" && component.TypeID != f.typeID {
+		return false
+	}
+	if f.typeName != "" && component.Type != f.typeName {
+		return false
+	}
+	if parent, _ := component.Parent.(string); f.parent != "" && parent != f.parent {
+		return false
+	}
+
+	if len(f.fields) > 0 {
+		fields := convertFields(component.Fields)
+		for k, v := range f.fields {
+			if value, ok := fields[k]; !ok || value != v {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 func flattenComponent(component *ardoq.Component) map[string]interface{} {
+	// fields_json can't fail to encode, the fields were decoded from JSON
+	fieldsJSON, _ := encodeFieldsJSON(component.Fields)
+
 	// d.Set("root_workspace", component.RootWorkspace)
 	// d.Set("name", component.Name)
 	// d.Set("description", component.Description)
@@ -119,8 +327,26 @@ func flattenComponent(component *ardoq.Component) map[string]interface{} {
 		"id":             component.ID,
 		"parent":         component.Parent,
 		"type_id":        component.TypeID,
+		"type_name":      component.Type,
 		"fields":         component.GetConvertedFields(), //TODO figure something out, that if there are no additional fields. the object "Fields: """ doesn't get added
 		// "fields":  component.Fields,
+		"fields_json": fieldsJSON,
+
+		"component_key":            component.ComponentKey,
+		"ardoq_persistent":         flattenPersistent(component.Ardoq.Persistent),
+		"children_count":           len(component.Children),
+		"incoming_reference_count": component.Ardoq.IncomingReferenceCount,
+		"outgoing_reference_count": component.Ardoq.OutgoingReferenceCount,
+
+		"created":                component.Created,
+		"created_by":             component.CreatedBy,
+		"created_by_email":       component.CreatedByEmail,
+		"created_by_name":        component.CreatedByName,
+		"last_updated":           component.LastUpdated2,
+		"last_modified_by":       component.LastModifiedBy,
+		"last_modified_by_email": component.LastModifiedByEmail,
+		"last_modified_by_name":  component.LastModifiedByName,
+		"version":                component.Version,
 	}
 }
 
@@ -1,32 +1,185 @@
 package provider
 
 import (
-	"regexp"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
-func TestAccDataSourceComponents(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
-
-	resource.UnitTest(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
-		Steps: []resource.TestStep{
-			{
-				Config: testAccDataSourceComponents,
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.scaffolding_data_source.foo", "sample_attribute", regexp.MustCompile("^ba")),
-				),
-			},
-		},
+func TestDataSourceComponent(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	id := f.put("component", map[string]interface{}{"name": "my-component", "rootWorkspace": workspace, "typeId": "p1", "type": "Application", "owner": "me",
+		"_version": 3, "component-key": "APP-1", "createdByName": "Someone", "last-updated": "2024-01-02T00:00:00Z"})
+	f.put("component", map[string]interface{}{"name": "other", "rootWorkspace": workspace, "parent": id})
+
+	state := testReadDataSource(t, dataSourceArdoqComponent(), map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "my-component",
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"id":           id,
+		"type_id":      "p1",
+		"type_name":    "Application",
+		"fields.owner": "me",
+		"fields_json":  `{"owner":"me"}`,
+
+		"version":         "3",
+		"component_key":   "APP-1",
+		"created_by_name": "Someone",
+		"last_updated":    "2024-01-02T00:00:00Z",
+		"children_count":  "1",
+	})
+}
+
+func TestDataSourceComponent_notFound(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	_, err := testReadDataSourceErr(dataSourceArdoqComponent(), map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "my-component",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), "0 components found") {
+		t.Fatalf("error = %v, want no components to be found", err)
+	}
+}
+
+func TestDataSourceComponent_lookup(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	platform := f.put("component", map[string]interface{}{"name": "Platform", "rootWorkspace": workspace})
+	payments := f.put("component", map[string]interface{}{"name": "Payments", "rootWorkspace": workspace, "parent": platform})
+	checkout := f.put("component", map[string]interface{}{"name": "Checkout API", "rootWorkspace": workspace, "parent": payments, "owner": "payments"})
+	shop := f.put("component", map[string]interface{}{"name": "Shop", "rootWorkspace": workspace, "parent": platform})
+	f.put("component", map[string]interface{}{"name": "Checkout API", "rootWorkspace": workspace, "parent": shop, "owner": "shop"})
+
+	for name, config := range map[string]map[string]interface{}{
+		"id":    {"id": checkout},
+		"path":  {"root_workspace": workspace, "path": "Platform/Payments/Checkout API"},
+		"field": {"root_workspace": workspace, "field": []interface{}{map[string]interface{}{"name": "owner", "value": "payments"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state := testReadDataSource(t, dataSourceArdoqComponent(), config, meta)
+			testCheckAttributes(t, state, map[string]string{
+				"id":             checkout,
+				"name":           "Checkout API",
+				"parent":         payments,
+				"root_workspace": workspace,
+			})
+		})
+	}
+}
+
+func TestDataSourceComponent_ambiguous(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	first := f.put("component", map[string]interface{}{"name": "Checkout API", "rootWorkspace": workspace})
+	second := f.put("component", map[string]interface{}{"name": "Checkout API", "rootWorkspace": workspace, "parent": first})
+
+	_, err := testReadDataSourceErr(dataSourceArdoqComponent(), map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "Checkout API",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), "2 components found") || !strings.Contains(err.Error(), fmt.Sprintf("%q", []string{first, second})) {
+		t.Fatalf("error = %v, want the ids of both components", err)
+	}
+
+	// Terraform validates the configuration before the plan
+	diags := dataSourceArdoqComponent().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "Checkout API",
+		"path":           "Checkout API",
+	}))
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), "only one of") {
+		t.Fatalf("diagnostics = %v, want only one of name and path to be allowed", diags)
+	}
+}
+
+func TestDataSourceComponents(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	other := f.put("workspace", map[string]interface{}{"name": "Other", "componentModel": "model1"})
+	f.put("component", map[string]interface{}{"name": "first", "rootWorkspace": workspace})
+	f.put("component", map[string]interface{}{"name": "second", "rootWorkspace": workspace})
+	f.put("component", map[string]interface{}{"name": "third", "rootWorkspace": other})
+
+	state := testReadDataSource(t, dataSourceArdoqComponents(), map[string]interface{}{
+		"root_workspace": workspace,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"components.#":      "2",
+		"components.0.name": "first",
+		"components.1.name": "second",
 	})
 }
 
-const testAccDataSourceComponents = `
-data "scaffolding_data_source" "foo" {
-  sample_attribute = "bar"
+func TestDataSourceComponents_filters(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	capability := f.put("component", map[string]interface{}{"name": "Sales", "rootWorkspace": workspace})
+	f.put("component", map[string]interface{}{"name": "CRM", "rootWorkspace": workspace, "typeId": "p1", "type": "Application", "parent": capability, "owner": "me", "cost": 10})
+	f.put("component", map[string]interface{}{"name": "CRM Database", "rootWorkspace": workspace, "parent": capability, "owner": "me"})
+	f.put("component", map[string]interface{}{"name": "ERP", "rootWorkspace": workspace, "typeId": "p1", "type": "Application", "owner": "you"})
+
+	for name, tc := range map[string]struct {
+		config map[string]interface{}
+		want   []string
+	}{
+		"name":        {config: map[string]interface{}{"name": "CRM"}, want: []string{"CRM"}},
+		"name_prefix": {config: map[string]interface{}{"name_prefix": "CRM"}, want: []string{"CRM", "CRM Database"}},
+		"name_regex":  {config: map[string]interface{}{"name_regex": "^(ERP|Sales)$"}, want: []string{"Sales", "ERP"}},
+		"type_id":     {config: map[string]interface{}{"type_id": "p1"}, want: []string{"CRM", "ERP"}},
+		"type_name":   {config: map[string]interface{}{"type_name": "Application", "parent": capability}, want: []string{"CRM"}},
+		"parent":      {config: map[string]interface{}{"parent": capability}, want: []string{"CRM", "CRM Database"}},
+		"fields":      {config: map[string]interface{}{"fields": map[string]interface{}{"owner": "me", "cost": "10"}}, want: []string{"CRM"}},
+		"no match":    {config: map[string]interface{}{"name_prefix": "HR"}, want: []string{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.config["root_workspace"] = workspace
+			state := testReadDataSource(t, dataSourceArdoqComponents(), tc.config, meta)
+
+			// the name is searched by Ardoq, the other filters are applied to the result
+			name, _ := tc.config["name"].(string)
+			if got := f.lastRequest(t, http.MethodGet, "component/search").Query.Get("name"); got != name {
+				t.Errorf("searched for name %q, want %q", got, name)
+			}
+
+			want := map[string]string{"components.#": fmt.Sprint(len(tc.want))}
+			for i, name := range tc.want {
+				want[fmt.Sprintf("components.%d.name", i)] = name
+			}
+			testCheckAttributes(t, state, want)
+		})
+	}
+}
+
+func TestDataSourceComponents_stableID(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	f.put("component", map[string]interface{}{"name": "CRM", "rootWorkspace": workspace})
+
+	config := func(prefix string) map[string]interface{} {
+		return map[string]interface{}{"root_workspace": workspace, "name_prefix": prefix}
+	}
+
+	first := testReadDataSource(t, dataSourceArdoqComponents(), config("C"), meta)
+	if second := testReadDataSource(t, dataSourceArdoqComponents(), config("C"), meta); second.ID != first.ID {
+		t.Errorf("id changed from %q to %q without changes", first.ID, second.ID)
+	}
+
+	// the same result for other arguments has another id
+	if other := testReadDataSource(t, dataSourceArdoqComponents(), config("CR"), meta); other.ID == first.ID {
+		t.Errorf("id %q is the same for other arguments", other.ID)
+	}
 }
-`
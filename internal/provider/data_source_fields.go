@@ -2,8 +2,6 @@ package provider
 
 import (
 	"context"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -90,6 +88,11 @@ var fieldSchema = map[string]*schema.Schema{
 		Type:        schema.TypeString,
 		Computed:    true,
 	},
+	"model": {
+		Description: "Id of the model the field belongs to",
+		Type:        schema.TypeString,
+		Computed:    true,
+	},
 	"name": {
 		Description: "Name of the field",
 		Type:        schema.TypeString,
@@ -130,18 +133,49 @@ var fieldSchema = map[string]*schema.Schema{
 }
 
 func dataSourceArdoqField() *schema.Resource {
+	// fieldSchema is shared with ardoq_fields, so the attributes are copied before they are changed
+	dsSchema := make(map[string]*schema.Schema, len(fieldSchema))
+	for k, v := range fieldSchema {
+		attribute := *v
+		dsSchema[k] = &attribute
+	}
+	addExactlyOneOfFieldsToSchema(dsSchema, "id", "name")
+	dsSchema["name"].RequiredWith = []string{"name", "model"}
+	dsSchema["model"].Optional = true
+	dsSchema["model"].Description = "Id of the model the field belongs to, required to look up a field by `name`"
+
 	return &schema.Resource{
-		Description: "`ardoq_field` returns a field",
+		Description: "`ardoq_field` returns a field by id, or by name and model",
 		ReadContext: dataSourceFieldRead,
-		Schema:      fieldSchema,
+		Schema:      dsSchema,
 	}
 }
 
 func dataSourceArdoqFields() *schema.Resource {
 	return &schema.Resource{
-		Description: "`ardoq_fields` returns all fields",
+		Description: "`ardoq_fields` returns all fields, optionally filtered by model, component type, reference type or type",
 		ReadContext: dataSourceFieldsRead,
 		Schema: map[string]*schema.Schema{
+			"model": {
+				Description: "Only return fields of the model with this id",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"component_type": {
+				Description: "Only return fields which apply to the component type with this id, including the global fields",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"reference_type": {
+				Description: "Only return fields which apply to the reference type with this id, including the global reference fields",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"type": {
+				Description: "Only return fields of this type, i.e. `Text` or `List`",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
 			"fields": {
 				// Description: "TODO", //TODOC
 				Type:     schema.TypeList,
@@ -158,12 +192,31 @@ func dataSourceFieldRead(ctx context.Context, d *schema.ResourceData, m interfac
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
-	fieldID := d.Get("id").(string)
+	c := m.(*apiClient)
 
-	field, err := c.Fields().Read(ctx, fieldID)
-	if err != nil {
-		return diag.FromErr(err)
+	var field *ardoq.Field
+	if name, ok := d.GetOk("name"); ok {
+		// names are unique within a model, Ardoq has no search for fields
+		fields, err := c.Fields().GetAll(ctx)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		model := d.Get("model").(string)
+		for i := range *fields {
+			if (*fields)[i].Name == name.(string) && (*fields)[i].Model == model {
+				field = &(*fields)[i]
+				break
+			}
+		}
+		if field == nil {
+			return diag.Errorf("field with name %q not found in model %s", name, model)
+		}
+	} else {
+		var err error
+		if field, err = c.Fields().Read(ctx, d.Get("id").(string)); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
 	flatField := flattenField(field)
@@ -182,23 +235,67 @@ func dataSourceFieldsRead(ctx context.Context, d *schema.ResourceData, m interfa
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 
 	fields, err := c.Fields().GetAll(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	if err := d.Set("fields", flattenFields(fields)); err != nil {
+	filter := expandFieldFilter(d)
+	matches := make([]ardoq.Field, 0, len(*fields))
+	for _, field := range *fields {
+		if filter.matches(&field) {
+			matches = append(matches, field)
+		}
+	}
+
+	flatFields := flattenFields(&matches)
+	if err := d.Set("fields", flatFields); err != nil {
 		return diag.FromErr(err)
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(dataSourceHashID(d, flatFields, "model", "component_type", "reference_type", "type"))
 
 	return diags
 }
 
+// fieldFilter holds the filters of the ardoq_fields data source, empty filters match every field
+type fieldFilter struct {
+	model         string
+	componentType string
+	referenceType string
+	fieldType     string
+}
+
+func expandFieldFilter(d *schema.ResourceData) fieldFilter {
+	return fieldFilter{
+		model:         d.Get("model").(string),
+		componentType: d.Get("component_type").(string),
+		referenceType: d.Get("reference_type").(string),
+		fieldType:     d.Get("type").(string),
+	}
+}
+
+// matches returns whether the field matches the filter, global fields apply to every component type
+// of the model and global reference fields apply to every reference type of the model
+func (f fieldFilter) matches(field *ardoq.Field) bool {
+	if f.model != "" && field.Model != f.model {
+		return false
+	}
+	if f.fieldType != "" && field.Type != f.fieldType {
+		return false
+	}
+	if f.componentType != "" && !field.Global && !containsString(field.ComponentType, f.componentType) {
+		return false
+	}
+	if f.referenceType != "" && !field.GlobalRef && !containsString(field.ReferenceType, f.referenceType) {
+		return false
+	}
+
+	return true
+}
+
 func flattenField(field *ardoq.Field) map[string]interface{} {
 	return map[string]interface{}{
 		// DateTimeFields
@@ -218,6 +315,7 @@ func flattenField(field *ardoq.Field) map[string]interface{} {
 		"last_modified_by_email": field.LastModifiedByEmail,
 		"last_modified_by_name":  field.LastModifiedByName,
 		"last_updated":           field.LastUpdated,
+		"model":                  field.Model,
 		"name":                   field.Name,
 		"order":                  field.Order,
 		"reference_type":         field.ReferenceType,
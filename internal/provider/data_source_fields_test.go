@@ -1,32 +1,90 @@
 package provider
 
 import (
-	"regexp"
+	"fmt"
+	"strings"
 	"testing"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
-func TestAccDataSourceFields(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
-
-	resource.UnitTest(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
-		Steps: []resource.TestStep{
-			{
-				Config: testAccDataSourceFields,
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.scaffolding_data_source.foo", "sample_attribute", regexp.MustCompile("^ba")),
-				),
-			},
-		},
+func TestDataSourceField(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	id := f.put("field", map[string]interface{}{"name": "status", "label": "Status", "type": fieldTypeList, "model": "model1", "componentType": []interface{}{"p1"}})
+
+	state := testReadDataSource(t, dataSourceArdoqField(), map[string]interface{}{
+		"id": id,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"name":             "status",
+		"label":            "Status",
+		"type":             fieldTypeList,
+		"component_type.#": "1",
 	})
 }
 
-const testAccDataSourceFields = `
-data "scaffolding_data_source" "foo" {
-  sample_attribute = "bar"
+func TestDataSourceFields(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	testFakeWorkspace(f)
+
+	state := testReadDataSource(t, dataSourceArdoqFields(), map[string]interface{}{}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"fields.#":      "2",
+		"fields.0.name": "owner",
+		"fields.1.name": "cost",
+	})
+}
+
+func TestDataSourceField_byName(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	testFakeWorkspace(f)
+	f.put("field", map[string]interface{}{"name": "owner", "type": fieldTypeText, "model": "model2"})
+
+	state := testReadDataSource(t, dataSourceArdoqField(), map[string]interface{}{
+		"name":  "owner",
+		"model": "model2",
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"name":  "owner",
+		"model": "model2",
+		"type":  fieldTypeText,
+	})
+
+	_, err := testReadDataSourceErr(dataSourceArdoqField(), map[string]interface{}{
+		"name":  "status",
+		"model": "model1",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), `field with name "status" not found in model model1`) {
+		t.Fatalf("error = %v, want the field not to be found", err)
+	}
+}
+
+func TestDataSourceFields_filters(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	f.put("field", map[string]interface{}{"name": "owner", "type": fieldTypeText, "model": "model1", "global": true, "globalref": true})
+	f.put("field", map[string]interface{}{"name": "status", "type": fieldTypeList, "model": "model1", "componentType": []interface{}{"p1"}})
+	f.put("field", map[string]interface{}{"name": "protocol", "type": fieldTypeText, "model": "model1", "referenceType": []interface{}{"2"}})
+	f.put("field", map[string]interface{}{"name": "other", "type": fieldTypeText, "model": "model2", "global": true})
+
+	for name, tc := range map[string]struct {
+		config map[string]interface{}
+		want   []string
+	}{
+		"model":          {config: map[string]interface{}{"model": "model2"}, want: []string{"other"}},
+		"component_type": {config: map[string]interface{}{"model": "model1", "component_type": "p1"}, want: []string{"owner", "status"}},
+		"reference_type": {config: map[string]interface{}{"model": "model1", "reference_type": "2"}, want: []string{"owner", "protocol"}},
+		"type":           {config: map[string]interface{}{"type": fieldTypeText}, want: []string{"owner", "protocol", "other"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state := testReadDataSource(t, dataSourceArdoqFields(), tc.config, meta)
+
+			want := map[string]string{"fields.#": fmt.Sprint(len(tc.want))}
+			for i, name := range tc.want {
+				want[fmt.Sprintf("fields.%d.name", i)] = name
+			}
+			testCheckAttributes(t, state, want)
+		})
+	}
 }
-`
@@ -2,8 +2,7 @@ package provider
 
 import (
 	"context"
-	"strconv"
-	"time"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -53,10 +52,22 @@ var modelSchema = map[string]*schema.Schema{
 }
 
 func dataSourceArdoqModel() *schema.Resource {
+	// modelSchema is shared with ardoq_models, so the attributes are copied before they are changed
+	dsSchema := make(map[string]*schema.Schema, len(modelSchema))
+	for k, v := range modelSchema {
+		attribute := *v
+		dsSchema[k] = &attribute
+	}
+	dsSchema["workspace_id"] = &schema.Schema{
+		Description: "Id of a workspace, the model the workspace is based on is returned",
+		Type:        schema.TypeString,
+	}
+	addExactlyOneOfFieldsToSchema(dsSchema, "id", "name", "workspace_id")
+
 	return &schema.Resource{
-		Description: "`ardoq_model` returns a model",
+		Description: "`ardoq_model` returns a model by id, by name or by a workspace based on the model",
 		ReadContext: dataSourceModelRead,
-		Schema:      modelSchema,
+		Schema:      dsSchema,
 	}
 }
 
@@ -81,10 +92,18 @@ func dataSourceModelRead(ctx context.Context, d *schema.ResourceData, m interfac
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
-	modelID := d.Get("id").(string)
-
-	model, err := c.Models().Read(ctx, modelID)
+	c := m.(*apiClient)
+
+	var model *ardoq.Model
+	var err error
+	switch {
+	case d.Get("workspace_id").(string) != "":
+		model, err = workspaceModel(ctx, c, d.Get("workspace_id").(string))
+	case d.Get("name").(string) != "":
+		model, err = modelByName(ctx, c, d.Get("name").(string))
+	default:
+		model, err = c.Models().Read(ctx, d.Get("id").(string))
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -101,23 +120,49 @@ func dataSourceModelRead(ctx context.Context, d *schema.ResourceData, m interfac
 	return diags
 }
 
+// modelByName returns the model with the name, it's an error when more models have the name
+func modelByName(ctx context.Context, c *apiClient, name string) (*ardoq.Model, error) {
+	models, err := c.Models().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var model *ardoq.Model
+	var ids []string
+	for i := range *models {
+		if (*models)[i].Name == name {
+			model = &(*models)[i]
+			ids = append(ids, model.ID)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return nil, fmt.Errorf("model with name %q not found", name)
+	case 1:
+		return model, nil
+	default:
+		return nil, fmt.Errorf("%d models found with name %q, the models found are %q, use the id to select one of them", len(ids), name, ids)
+	}
+}
+
 func dataSourceModelsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 
 	models, err := c.Models().GetAll(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	if err := d.Set("models", flattenModels(models)); err != nil {
+	flatModels := flattenModels(models)
+	if err := d.Set("models", flatModels); err != nil {
 		return diag.FromErr(err)
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(dataSourceHashID(d, flatModels))
 
 	return diags
 }
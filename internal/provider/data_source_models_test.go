@@ -1,32 +1,64 @@
 package provider
 
 import (
-	"regexp"
+	"strings"
 	"testing"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
-func TestAccDataSourceModels(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
-
-	resource.UnitTest(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
-		Steps: []resource.TestStep{
-			{
-				Config: testAccDataSourceModels,
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.scaffolding_data_source.foo", "sample_attribute", regexp.MustCompile("^ba")),
-				),
-			},
-		},
+func TestDataSourceModel(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	testFakeWorkspace(f)
+
+	state := testReadDataSource(t, dataSourceArdoqModel(), map[string]interface{}{
+		"id": "model1",
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"name":                        "Test",
+		"component_types.Application": "p1",
+		"reference_types.Implicit":    "2",
 	})
 }
 
-const testAccDataSourceModels = `
-data "scaffolding_data_source" "foo" {
-  sample_attribute = "bar"
+func TestDataSourceModel_lookup(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	f.put("model", map[string]interface{}{"_id": "model2", "name": "Other"})
+
+	for name, config := range map[string]map[string]interface{}{
+		"name":         {"name": "Test"},
+		"workspace_id": {"workspace_id": workspace},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state := testReadDataSource(t, dataSourceArdoqModel(), config, meta)
+			testCheckAttributes(t, state, map[string]string{
+				"id":                          "model1",
+				"name":                        "Test",
+				"component_types.Application": "p1",
+			})
+		})
+	}
+
+	f.put("model", map[string]interface{}{"_id": "model3", "name": "Other"})
+	_, err := testReadDataSourceErr(dataSourceArdoqModel(), map[string]interface{}{
+		"name": "Other",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), `2 models found with name "Other", the models found are ["model2" "model3"]`) {
+		t.Fatalf("error = %v, want the ids of both models", err)
+	}
+}
+
+func TestDataSourceModels(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	testFakeWorkspace(f)
+	f.put("model", map[string]interface{}{"_id": "model2", "name": "Other"})
+
+	state := testReadDataSource(t, dataSourceArdoqModels(), map[string]interface{}{}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"models.#":      "2",
+		"models.0.name": "Test",
+		"models.1.name": "Other",
+	})
 }
-`
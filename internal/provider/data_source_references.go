@@ -2,8 +2,6 @@ package provider
 
 import (
 	"context"
-	"strconv"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -25,9 +23,34 @@ func dataSourceArdoqReferences() *schema.Resource {
 	dsSchema := datasourceSchemaFromResourceSchema(resourceArdoqReference().Schema)
 
 	return &schema.Resource{
-		Description: "`arodq_references` returns all references",
+		Description: "`arodq_references` returns the references of a workspace, optionally filtered by source, target or type. Without filters it returns all references of the organization",
 		ReadContext: dataSourceReferencesRead,
 		Schema: map[string]*schema.Schema{
+			"root_workspace": {
+				Description: "Only return references from this workspace",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"target_workspace": {
+				Description: "Only return references to this workspace",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"source": {
+				Description: "Only return references from the component with this id",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"target": {
+				Description: "Only return references to the component with this id",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"type": {
+				Description: "Only return references of this type",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
 			"references": {
 				Description: "References describe relationship between components. References can have types (defined by the model) to represent different kinds of relationship i.e. Synchronized or Asynchroinzed.",
 				Type:        schema.TypeList,
@@ -44,7 +67,7 @@ func dataSourceReferenceRead(ctx context.Context, d *schema.ResourceData, m inte
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 	referenceID := d.Get("id").(string)
 
 	reference, err := c.References().Read(ctx, referenceID)
@@ -69,24 +92,98 @@ func dataSourceReferencesRead(ctx context.Context, d *schema.ResourceData, m int
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
+
+	// references always belong to the workspace of their source, so only the references of that
+	// workspace are retrieved when it's known, the other filters are applied to the result
+	rootWorkspace := d.Get("root_workspace").(string)
+	if source := d.Get("source").(string); rootWorkspace == "" && source != "" {
+		component, err := c.Components().Read(ctx, source)
+		if err != nil {
+			return diag.Errorf("could not read source component %s: %s", source, err)
+		}
+		rootWorkspace = component.RootWorkspace
+	}
 
-	references, err := c.References().GetAll(ctx)
+	var references *[]ardoq.Reference
+	var err error
+	if rootWorkspace != "" {
+		references, err = c.Workspaces().References(ctx, rootWorkspace)
+	} else {
+		references, err = c.References().GetAll(ctx)
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	if err := d.Set("references", flattenReferences(references)); err != nil {
+	filter := expandReferenceFilter(d)
+	matches := make([]ardoq.Reference, 0, len(*references))
+	for _, reference := range *references {
+		if filter.matches(&reference) {
+			matches = append(matches, reference)
+		}
+	}
+
+	flatReferences := flattenReferences(&matches)
+	if err := d.Set("references", flatReferences); err != nil {
 		return diag.FromErr(err)
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(dataSourceHashID(d, flatReferences, "root_workspace", "target_workspace", "source", "target", "type"))
 
 	return diags
 }
 
+// referenceFilter holds the filters of the ardoq_references data source, empty filters match every reference
+type referenceFilter struct {
+	rootWorkspace   string
+	targetWorkspace string
+	source          string
+	target          string
+	typeID          *int
+}
+
+func expandReferenceFilter(d *schema.ResourceData) referenceFilter {
+	filter := referenceFilter{
+		rootWorkspace:   d.Get("root_workspace").(string),
+		targetWorkspace: d.Get("target_workspace").(string),
+		source:          d.Get("source").(string),
+		target:          d.Get("target").(string),
+	}
+
+	// 0 is a valid type, so it has to be checked whether the type is set at all
+	if v, ok := d.GetOkExists("type"); ok { //nolint:staticcheck
+		typeID := v.(int)
+		filter.typeID = &typeID
+	}
+
+	return filter
+}
+
+func (f referenceFilter) matches(reference *ardoq.Reference) bool {
+	if f.rootWorkspace != "" && reference.RootWorkspace != f.rootWorkspace {
+		return false
+	}
+	if f.targetWorkspace != "" && reference.TargetWorkspace != f.targetWorkspace {
+		return false
+	}
+	if f.source != "" && reference.Source != f.source {
+		return false
+	}
+	if f.target != "" && reference.Target != f.target {
+		return false
+	}
+	if f.typeID != nil && reference.Type != *f.typeID {
+		return false
+	}
+
+	return true
+}
+
 func flattenReference(reference *ardoq.Reference) map[string]interface{} {
+	// fields_json can't fail to encode, the fields were decoded from JSON
+	fieldsJSON, _ := encodeFieldsJSON(reference.Fields)
+
 	return map[string]interface{}{
 		"root_workspace":   reference.RootWorkspace,
 		"source":           reference.Source,
@@ -96,6 +193,18 @@ func flattenReference(reference *ardoq.Reference) map[string]interface{} {
 		"description":      reference.Description,
 		"display_text":     reference.DisplayText,
 		"id":               reference.ID,
+		"fields":           convertFields(reference.Fields),
+		"fields_json":      fieldsJSON,
+
+		"created":                reference.Created,
+		"created_by":             reference.CreatedBy,
+		"created_by_email":       reference.CreatedByEmail,
+		"created_by_name":        reference.CreatedByName,
+		"last_updated":           reference.LastUpdated2,
+		"last_modified_by":       reference.LastModifiedBy,
+		"last_modified_by_email": reference.LastModifiedByEmail,
+		"last_modified_by_name":  reference.LastModifiedByName,
+		"version":                reference.Version,
 	}
 }
 
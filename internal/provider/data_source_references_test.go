@@ -1,32 +1,108 @@
 package provider
 
 import (
-	"regexp"
+	"fmt"
+	"net/http"
 	"testing"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
-func TestAccDataSourceReferences(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
-
-	resource.UnitTest(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
-		Steps: []resource.TestStep{
-			{
-				Config: testAccDataSourceReferences,
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.scaffolding_data_source.foo", "sample_attribute", regexp.MustCompile("^ba")),
-				),
-			},
-		},
+func TestDataSourceReference(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	id := f.put("reference", map[string]interface{}{"source": "a", "target": "b", "rootWorkspace": workspace, "targetWorkspace": workspace, "type": 2, "displayText": "uses", "owner": "me", "cost": 10, "_version": 2, "created-by": "user1"})
+
+	state := testReadDataSource(t, dataSourceArdoqReference(), map[string]interface{}{
+		"id": id,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"source":       "a",
+		"target":       "b",
+		"type":         "2",
+		"display_text": "uses",
+		"fields.%":     "2",
+		"fields.owner": "me",
+		"fields.cost":  "10",
+		"fields_json":  `{"cost":10,"owner":"me"}`,
+		"version":      "2",
+		"created_by":   "user1",
+	})
+}
+
+func TestDataSourceReferences(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	f.put("reference", map[string]interface{}{"source": "a", "target": "b", "rootWorkspace": workspace, "targetWorkspace": workspace, "type": 2, "owner": "me"})
+	f.put("reference", map[string]interface{}{"source": "b", "target": "c", "rootWorkspace": workspace, "targetWorkspace": workspace, "type": 2})
+
+	state := testReadDataSource(t, dataSourceArdoqReferences(), map[string]interface{}{}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"references.#":              "2",
+		"references.0.source":       "a",
+		"references.0.fields.owner": "me",
+		"references.1.source":       "b",
+		"references.1.fields.%":     "0",
 	})
 }
 
-const testAccDataSourceReferences = `
-data "scaffolding_data_source" "foo" {
-  sample_attribute = "bar"
+func TestDataSourceReferences_filters(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	other := f.put("workspace", map[string]interface{}{"name": "Other", "componentModel": "model1"})
+	a := f.put("component", map[string]interface{}{"name": "a", "rootWorkspace": workspace})
+	b := f.put("component", map[string]interface{}{"name": "b", "rootWorkspace": workspace})
+	c := f.put("component", map[string]interface{}{"name": "c", "rootWorkspace": other})
+	f.put("reference", map[string]interface{}{"source": a, "target": b, "rootWorkspace": workspace, "targetWorkspace": workspace, "type": 0})
+	f.put("reference", map[string]interface{}{"source": a, "target": c, "rootWorkspace": workspace, "targetWorkspace": other, "type": 2})
+	f.put("reference", map[string]interface{}{"source": b, "target": c, "rootWorkspace": workspace, "targetWorkspace": other, "type": 2})
+	f.put("reference", map[string]interface{}{"source": c, "target": a, "rootWorkspace": other, "targetWorkspace": workspace, "type": 0})
+
+	for name, tc := range map[string]struct {
+		config map[string]interface{}
+		path   string
+		want   [][2]string
+	}{
+		"root_workspace": {
+			config: map[string]interface{}{"root_workspace": workspace},
+			path:   "workspace/" + workspace + "/reference",
+			want:   [][2]string{{a, b}, {a, c}, {b, c}},
+		},
+		"target_workspace": {
+			config: map[string]interface{}{"root_workspace": workspace, "target_workspace": other},
+			path:   "workspace/" + workspace + "/reference",
+			want:   [][2]string{{a, c}, {b, c}},
+		},
+		"source": {
+			config: map[string]interface{}{"source": a},
+			path:   "workspace/" + workspace + "/reference",
+			want:   [][2]string{{a, b}, {a, c}},
+		},
+		"target": {
+			config: map[string]interface{}{"target": a},
+			path:   "reference",
+			want:   [][2]string{{c, a}},
+		},
+		"type": {
+			config: map[string]interface{}{"type": 0},
+			path:   "reference",
+			want:   [][2]string{{a, b}, {c, a}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			count := f.requestCount(http.MethodGet, tc.path)
+			state := testReadDataSource(t, dataSourceArdoqReferences(), tc.config, meta)
+			if f.requestCount(http.MethodGet, tc.path) != count+1 {
+				t.Errorf("references were not retrieved with GET %s", tc.path)
+			}
+
+			want := map[string]string{"references.#": fmt.Sprint(len(tc.want))}
+			for i, reference := range tc.want {
+				want[fmt.Sprintf("references.%d.source", i)] = reference[0]
+				want[fmt.Sprintf("references.%d.target", i)] = reference[1]
+			}
+			testCheckAttributes(t, state, want)
+		})
+	}
 }
-`
@@ -0,0 +1,424 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	tabularFormatCSV  = "csv"
+	tabularFormatJSON = "json"
+)
+
+// tabularAttributes are the attributes of a component a column can be mapped to
+var tabularAttributes = []string{"name", "description", "type_id", "type_name", "parent", "parent_name"}
+
+func dataSourceArdoqTabularSource() *schema.Resource {
+	return &schema.Resource{
+		Description: "`ardoq_tabular_source` data source reads a CSV or JSON file and maps its rows to components, " +
+			"which can be used with `for_each` on an `ardoq_component` or with the `component` blocks of an `ardoq_component_set`. " +
+			"Rows which can't be mapped are reported with their row number",
+		ReadContext: dataSourceArdoqTabularSourceRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "Hash of the arguments and the components",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"path": {
+				Description:  "Path of the file to read, conflicts with `content`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"path", "content"},
+			},
+			"content": {
+				Description:  "Content to read instead of a file, conflicts with `path`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"path", "content"},
+			},
+			"format": {
+				Description: "Format of the content, `csv` or `json`. When not set, files ending with `.json` are read as JSON and everything else as CSV. " +
+					"A CSV has a header row with the column names, JSON is an array of objects, their keys are the column names",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{tabularFormatCSV, tabularFormatJSON}, false),
+			},
+			"root_workspace": {
+				Description: "Id of the workspace, copied to every component",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"column": {
+				Description: "Mapping of a column to an attribute or custom field of the components, columns without mapping are ignored",
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Name of the column",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"attribute": {
+							Description:  "Attribute the column is mapped to, one of `" + strings.Join(tabularAttributes, "`, `") + "`. Conflicts with `field`",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice(tabularAttributes, false),
+						},
+						"field": {
+							Description: "Custom field the column is mapped to. Conflicts with `attribute`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"required": {
+							Description: "Whether every row needs a value for the column, the column mapped to `name` is always required",
+							Type:        schema.TypeBool,
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"components": {
+				Description: "The components, one for every row",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"row": {
+							Description: "Number of the row, the header of a CSV is row 1, the first object of JSON is row 1",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"root_workspace": {
+							Description: "Id of the workspace the component belongs to",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"name": {
+							Description: "Name of the component",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"description": {
+							Description: "Text field describing the component",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"type_id": {
+							Description: "Id of the component's type",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"type_name": {
+							Description: "Name of the component's type",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"parent": {
+							Description: "Id of the component's parent",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"parent_name": {
+							Description: "Name of the component's parent",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"fields": {
+							Description: "The custom fields of the component",
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// tabularColumn is the mapping of a column
+type tabularColumn struct {
+	name      string
+	attribute string
+	field     string
+	required  bool
+}
+
+// tabularRow is a row of the content, with its row number and the values by column name
+type tabularRow struct {
+	number int
+	values map[string]string
+}
+
+func dataSourceArdoqTabularSourceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	content := []byte(d.Get("content").(string))
+	format := d.Get("format").(string)
+
+	if path, ok := d.GetOk("path"); ok {
+		var err error
+		if content, err = os.ReadFile(path.(string)); err != nil {
+			return diag.Errorf("could not read %s: %s", path, err)
+		}
+		if format == "" && strings.EqualFold(filepath.Ext(path.(string)), ".json") {
+			format = tabularFormatJSON
+		}
+	}
+	if format == "" {
+		format = tabularFormatCSV
+	}
+
+	columns, err := expandTabularColumns(d.Get("column").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var header []string
+	var rows []tabularRow
+	switch format {
+	case tabularFormatJSON:
+		header, rows, err = readTabularJSON(content)
+	default:
+		header, rows, err = readTabularCSV(content)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the columns of the mapping have to exist, even when they are empty in every row
+	known := make(map[string]bool, len(header))
+	for _, column := range header {
+		known[column] = true
+	}
+	for _, column := range columns {
+		if !known[column.name] {
+			return diag.Errorf("column %q not found, the columns are %q", column.name, header)
+		}
+	}
+
+	components := make([]interface{}, 0, len(rows))
+	rowsByName := make(map[string]int, len(rows))
+	for _, row := range rows {
+		component, rowDiags := mapTabularRow(row, columns)
+		if name := component["name"].(string); name != "" {
+			if first, ok := rowsByName[name]; ok {
+				rowDiags = append(rowDiags, diag.Errorf("row %d: name %q is already used in row %d", row.number, name, first)...)
+			} else {
+				rowsByName[name] = row.number
+			}
+		}
+
+		diags = append(diags, rowDiags...)
+		component["root_workspace"] = d.Get("root_workspace").(string)
+		components = append(components, component)
+	}
+
+	// a parent by name has to be one of the rows, a parent outside the content is set by id
+	for _, component := range components {
+		component := component.(map[string]interface{})
+		if parentName := component["parent_name"].(string); parentName != "" {
+			if _, ok := rowsByName[parentName]; !ok {
+				diags = append(diags, diag.Errorf("row %d: parent_name %q is not the name of a row", component["row"], parentName)...)
+			}
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := d.Set("format", format); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("components", components); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(dataSourceHashID(d, components, "path", "content", "format", "root_workspace", "column"))
+
+	return diags
+}
+
+func expandTabularColumns(list []interface{}) ([]tabularColumn, error) {
+	columns := make([]tabularColumn, 0, len(list))
+	mapped := make(map[string]string)
+
+	for _, v := range list {
+		column := v.(map[string]interface{})
+		c := tabularColumn{
+			name:      column["name"].(string),
+			attribute: column["attribute"].(string),
+			field:     column["field"].(string),
+			required:  column["required"].(bool),
+		}
+
+		if (c.attribute == "") == (c.field == "") {
+			return nil, fmt.Errorf("column %q: exactly one of attribute and field has to be set", c.name)
+		}
+
+		// every attribute and field can only have one column
+		target := "attribute " + c.attribute
+		if c.field != "" {
+			target = "field " + c.field
+		}
+		if other, ok := mapped[target]; ok {
+			return nil, fmt.Errorf("column %q: %s is already mapped to column %q", c.name, target, other)
+		}
+		mapped[target] = c.name
+
+		if c.attribute == "name" {
+			c.required = true
+		}
+
+		columns = append(columns, c)
+	}
+
+	if _, ok := mapped["attribute name"]; !ok {
+		return nil, fmt.Errorf("a column has to be mapped to the attribute name")
+	}
+
+	return columns, nil
+}
+
+// mapTabularRow returns the component of a row, with a diagnostic for every problem in the row
+func mapTabularRow(row tabularRow, columns []tabularColumn) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	component := map[string]interface{}{
+		"row":    row.number,
+		"fields": map[string]interface{}{},
+	}
+	for _, attribute := range tabularAttributes {
+		component[attribute] = ""
+	}
+
+	for _, column := range columns {
+		value := row.values[column.name]
+		if value == "" {
+			if column.required {
+				diags = append(diags, diag.Errorf("row %d: column %q is required", row.number, column.name)...)
+			}
+			continue
+		}
+
+		if column.field != "" {
+			component["fields"].(map[string]interface{})[column.field] = value
+		} else {
+			component[column.attribute] = value
+		}
+	}
+
+	if component["type_id"] != "" && component["type_name"] != "" {
+		diags = append(diags, diag.Errorf("row %d: only one of type_id and type_name can be set", row.number)...)
+	}
+	if component["parent"] != "" && component["parent_name"] != "" {
+		diags = append(diags, diag.Errorf("row %d: only one of parent and parent_name can be set", row.number)...)
+	}
+
+	return component, diags
+}
+
+// readTabularCSV returns the header and the rows of a CSV, the header is row 1
+func readTabularCSV(content []byte) ([]string, []tabularRow, error) {
+	r := csv.NewReader(bytes.NewReader(content))
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("the CSV has no header")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read the CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	// a byte order mark is added by some spreadsheet applications
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	var rows []tabularRow
+	for number := 2; ; number++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("row %d: %w", number, err)
+		}
+
+		row := tabularRow{number: number, values: make(map[string]string, len(header))}
+		for i, column := range header {
+			row.values[column] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows, nil
+}
+
+// readTabularJSON returns the keys and the objects of a JSON array, the first object is row 1.
+// Values which aren't a string are converted to their JSON representation
+func readTabularJSON(content []byte) ([]string, []tabularRow, error) {
+	var objects []map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, nil, fmt.Errorf("could not read the JSON, it has to be an array of objects: %w", err)
+	}
+
+	keys := make(map[string]bool)
+	rows := make([]tabularRow, 0, len(objects))
+	for i, object := range objects {
+		row := tabularRow{number: i + 1, values: make(map[string]string, len(object))}
+		for key, value := range object {
+			keys[key] = true
+
+			switch v := value.(type) {
+			case nil:
+				row.values[key] = ""
+			case string:
+				row.values[key] = strings.TrimSpace(v)
+			case json.Number:
+				row.values[key] = v.String()
+			case bool:
+				row.values[key] = strconv.FormatBool(v)
+			default:
+				b, err := json.Marshal(v)
+				if err != nil {
+					return nil, nil, fmt.Errorf("row %d: %w", row.number, err)
+				}
+				row.values[key] = string(b)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	header := make([]string, 0, len(keys))
+	for key := range keys {
+		header = append(header, key)
+	}
+	sort.Strings(header)
+
+	return header, rows, nil
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testTabularColumns() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"name": "Name", "attribute": "name"},
+		map[string]interface{}{"name": "Type", "attribute": "type_name", "required": true},
+		map[string]interface{}{"name": "Parent", "attribute": "parent_name"},
+		map[string]interface{}{"name": "Owner", "field": "owner"},
+	}
+}
+
+func TestDataSourceTabularSource_csv(t *testing.T) {
+	state := testReadDataSource(t, dataSourceArdoqTabularSource(), map[string]interface{}{
+		"root_workspace": "workspace1",
+		"content": "Name,Type,Parent,Owner,Ignored\n" +
+			"CRM,Application,,Sales,x\n" +
+			"\"CRM, database\",Database,CRM,,y\n",
+		"column": testTabularColumns(),
+	}, nil)
+	testCheckAttributes(t, state, map[string]string{
+		"format":                      "csv",
+		"components.#":                "2",
+		"components.0.row":            "2",
+		"components.0.name":           "CRM",
+		"components.0.type_name":      "Application",
+		"components.0.parent_name":    "",
+		"components.0.root_workspace": "workspace1",
+		"components.0.fields.%":       "1",
+		"components.0.fields.owner":   "Sales",
+		"components.1.row":            "3",
+		"components.1.name":           "CRM, database",
+		"components.1.type_name":      "Database",
+		"components.1.parent_name":    "CRM",
+		"components.1.fields.%":       "0",
+	})
+}
+
+func TestDataSourceTabularSource_jsonFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "components.json")
+	content := `[
+  {"Name": "CRM", "Type": "Application", "Owner": "Sales", "Cost": 10},
+  {"Name": "ERP", "Type": "Application", "Owner": null, "Cost": 2.5}
+]`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	state := testReadDataSource(t, dataSourceArdoqTabularSource(), map[string]interface{}{
+		"path": path,
+		"column": []interface{}{
+			map[string]interface{}{"name": "Name", "attribute": "name"},
+			map[string]interface{}{"name": "Type", "attribute": "type_name"},
+			map[string]interface{}{"name": "Owner", "field": "owner"},
+			map[string]interface{}{"name": "Cost", "field": "cost"},
+		},
+	}, nil)
+	testCheckAttributes(t, state, map[string]string{
+		"format":                    "json",
+		"components.#":              "2",
+		"components.0.row":          "1",
+		"components.0.fields.owner": "Sales",
+		"components.0.fields.cost":  "10",
+		"components.1.name":         "ERP",
+		"components.1.fields.%":     "1",
+		"components.1.fields.cost":  "2.5",
+	})
+}
+
+func TestDataSourceTabularSource_invalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content string
+		columns []interface{}
+		want    []string
+	}{
+		"bad rows": {
+			content: "Name,Type,Parent,Owner\n" +
+				"CRM,Application,,\n" +
+				",Application,,\n" +
+				"CRM,,,\n" +
+				"ERP,Application,Finance,\n",
+			columns: testTabularColumns(),
+			want: []string{
+				`row 3: column "Name" is required`,
+				`row 4: column "Type" is required`,
+				`row 4: name "CRM" is already used in row 2`,
+				`row 5: parent_name "Finance" is not the name of a row`,
+			},
+		},
+		"missing column": {
+			content: "Name,Type\nCRM,Application\n",
+			columns: testTabularColumns(),
+			want:    []string{`column "Parent" not found, the columns are ["Name" "Type"]`},
+		},
+		"no name": {
+			content: "Name\nCRM\n",
+			columns: []interface{}{
+				map[string]interface{}{"name": "Name", "field": "name"},
+			},
+			want: []string{"a column has to be mapped to the attribute name"},
+		},
+		"attribute and field": {
+			content: "Name\nCRM\n",
+			columns: []interface{}{
+				map[string]interface{}{"name": "Name", "attribute": "name", "field": "name"},
+			},
+			want: []string{`column "Name": exactly one of attribute and field has to be set`},
+		},
+		"malformed csv": {
+			content: "Name,Type\nCRM\n",
+			columns: []interface{}{
+				map[string]interface{}{"name": "Name", "attribute": "name"},
+			},
+			want: []string{"row 2: "},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := testReadDataSourceErr(dataSourceArdoqTabularSource(), map[string]interface{}{
+				"content": tc.content,
+				"column":  tc.columns,
+			}, nil)
+			if err == nil {
+				t.Fatalf("got no error, want %q", tc.want)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got error %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,8 @@ package provider
 
 import (
 	"context"
-	"strconv"
-	"time"
+	"fmt"
+	"net/http"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -52,10 +52,18 @@ var workspaceSchema = map[string]*schema.Schema{
 }
 
 func dataSourceArdoqWorkspace() *schema.Resource {
+	// workspaceSchema is shared with ardoq_workspaces, so the attributes are copied before they are changed
+	dsSchema := make(map[string]*schema.Schema, len(workspaceSchema))
+	for k, v := range workspaceSchema {
+		attribute := *v
+		dsSchema[k] = &attribute
+	}
+	addExactlyOneOfFieldsToSchema(dsSchema, "id", "name")
+
 	return &schema.Resource{
-		Description: "`arodq_workspace` data source returns a workspace",
+		Description: "`arodq_workspace` data source returns a workspace by id or by name",
 		ReadContext: dataSourceWorkspaceRead,
-		Schema:      workspaceSchema,
+		Schema:      dsSchema,
 	}
 }
 
@@ -77,16 +85,49 @@ func dataSourceArdoqWorkspaces() *schema.Resource {
 }
 
 func dataSourceWorkspaceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(ardoq.Client)
-	workspaceName := d.Get("name").(string)
+	c := m.(*apiClient)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	workspace, err := c.Workspaces().Search(ctx, &ardoq.WorkspaceSearchQuery{Name: workspaceName})
+	var workspace *ardoq.Workspace
+	if id, ok := d.GetOk("id"); ok {
+		var err error
+		workspace, err = c.Workspaces().Get(ctx, id.(string))
+		if isAPIErrorWithCode(err, http.StatusNotFound) {
+			return diag.Errorf("workspace %s not found", id)
+		}
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	} else {
+		workspaceName := d.Get("name").(string)
 
-	if err != nil {
-		return diag.FromErr(err)
+		// the search of Ardoq returns one workspace, even when more workspaces have the name
+		workspaces, err := c.Workspaces().List(ctx, &ardoq.WorkspaceSearchQuery{})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		var ids []string
+		for i := range *workspaces {
+			if (*workspaces)[i].Name == workspaceName {
+				workspace = &(*workspaces)[i]
+				ids = append(ids, workspace.ID)
+			}
+		}
+
+		switch len(ids) {
+		case 0:
+			return diag.Errorf("workspace with name %q not found", workspaceName)
+		case 1:
+		default:
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("%d workspaces found with name %q, ardoq_workspace should return 1", len(ids), workspaceName),
+				Detail:   fmt.Sprintf("The workspaces found are %q, use the id to select one of them", ids),
+			})
+		}
 	}
 
 	flatWorkspace := flattenWorkspace(workspace)
@@ -102,7 +143,7 @@ func dataSourceWorkspaceRead(ctx context.Context, d *schema.ResourceData, m inte
 }
 
 func dataSourceWorkspacesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
@@ -112,12 +153,12 @@ func dataSourceWorkspacesRead(ctx context.Context, d *schema.ResourceData, m int
 		return diag.FromErr(err)
 	}
 
-	if err := d.Set("workspaces", flattenWorkspaces(workspaces)); err != nil {
+	flatWorkspaces := flattenWorkspaces(workspaces)
+	if err := d.Set("workspaces", flatWorkspaces); err != nil {
 		return diag.FromErr(err)
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId(dataSourceHashID(d, flatWorkspaces))
 
 	return diags
 }
@@ -130,7 +171,7 @@ func flattenWorkspace(workspace *ardoq.Workspace) map[string]interface{} {
 		"component_template": workspace.ComponentTemplate,
 		"component_counter":  workspace.CompCounter,
 		"description":        workspace.Description,
-		"fields":             workspace.Fields,
+		"fields":             convertFields(workspace.Fields),
 	}
 }
 
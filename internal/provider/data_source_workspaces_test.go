@@ -1,32 +1,96 @@
 package provider
 
 import (
-	"regexp"
+	"fmt"
+	"strings"
 	"testing"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
-func TestAccDataSourceWorkspaces(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
-
-	resource.UnitTest(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
-		Steps: []resource.TestStep{
-			{
-				Config: testAccDataSourceWorkspaces,
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.scaffolding_data_source.foo", "sample_attribute", regexp.MustCompile("^ba")),
-				),
-			},
-		},
+func TestDataSourceWorkspace(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	id := testFakeWorkspace(f)
+	f.put("workspace", map[string]interface{}{"name": "Other", "componentModel": "model1"})
+
+	state := testReadDataSource(t, dataSourceArdoqWorkspace(), map[string]interface{}{
+		"name": "Test",
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"id":              id,
+		"component_model": "model1",
 	})
 }
 
-const testAccDataSourceWorkspaces = `
-data "scaffolding_data_source" "foo" {
-  sample_attribute = "bar"
+func TestDataSourceWorkspace_byID(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	id := testFakeWorkspace(f)
+
+	state := testReadDataSource(t, dataSourceArdoqWorkspace(), map[string]interface{}{
+		"id": id,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"id":   id,
+		"name": "Test",
+	})
+
+	_, err := testReadDataSourceErr(dataSourceArdoqWorkspace(), map[string]interface{}{
+		"id": "unknown",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), "workspace unknown not found") {
+		t.Fatalf("error = %v, want the workspace not to be found", err)
+	}
+}
+
+func TestDataSourceWorkspace_ambiguous(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	first := testFakeWorkspace(f)
+	second := f.put("workspace", map[string]interface{}{"name": "Test", "componentModel": "model1"})
+
+	_, err := testReadDataSourceErr(dataSourceArdoqWorkspace(), map[string]interface{}{
+		"name": "Test",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), "2 workspaces found") || !strings.Contains(err.Error(), fmt.Sprintf("%q", []string{first, second})) {
+		t.Fatalf("error = %v, want the ids of both workspaces", err)
+	}
+
+	_, err = testReadDataSourceErr(dataSourceArdoqWorkspace(), map[string]interface{}{
+		"name": "Unknown",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), `workspace with name "Unknown" not found`) {
+		t.Fatalf("error = %v, want the workspace not to be found", err)
+	}
+}
+
+func TestDataSourceWorkspaces(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	testFakeWorkspace(f)
+	f.put("workspace", map[string]interface{}{"name": "Other", "componentModel": "model1"})
+
+	state := testReadDataSource(t, dataSourceArdoqWorkspaces(), map[string]interface{}{}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"workspaces.#":      "2",
+		"workspaces.0.name": "Test",
+		"workspaces.1.name": "Other",
+	})
+}
+
+func TestDataSourceWorkspaces_stableID(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	testFakeWorkspace(f)
+
+	first := testReadDataSource(t, dataSourceArdoqWorkspaces(), map[string]interface{}{}, meta)
+	second := testReadDataSource(t, dataSourceArdoqWorkspaces(), map[string]interface{}{}, meta)
+	if first.ID != second.ID {
+		t.Errorf("id changed from %q to %q without changes", first.ID, second.ID)
+	}
+
+	f.put("workspace", map[string]interface{}{"name": "Other", "componentModel": "model1"})
+	third := testReadDataSource(t, dataSourceArdoqWorkspaces(), map[string]interface{}{}, meta)
+	if third.ID == first.ID {
+		t.Errorf("id %q didn't change when a workspace was added", third.ID)
+	}
 }
-`
@@ -1,6 +1,10 @@
 package provider
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -71,8 +75,7 @@ func addRequiredFieldsToSchema(schema map[string]*schema.Schema, keys ...string)
 // addExactlyOneOfFieldsToSchema is a convenience func that sets a list of keys Optional & ExactlyOneOf.
 // This is useful when the schema has been generated (using `datasourceSchemaFromResourceSchema` above for
 // example) and the datasource could take one multiple inputs (say a unique name or a unique id)
-// this function is not used yet, but keep it around for reference
-func addExactlyOneOfFieldsToSchema(schema map[string]*schema.Schema, keys ...string) { //nolint:deadcode,unused
+func addExactlyOneOfFieldsToSchema(schema map[string]*schema.Schema, keys ...string) {
 	for _, v := range keys {
 		schema[v].Computed = false
 		schema[v].Optional = true
@@ -80,3 +83,19 @@ func addExactlyOneOfFieldsToSchema(schema map[string]*schema.Schema, keys ...str
 		schema[v].ExactlyOneOf = keys
 	}
 }
+
+// dataSourceHashID returns the id of a data source which returns a list, a hash of the arguments with
+// the given keys and the result. The id only changes when the arguments or the result change, so an
+// unchanged data source doesn't show up in the plan
+func dataSourceHashID(d *schema.ResourceData, result interface{}, keys ...string) string {
+	args := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		args[key] = d.Get(key)
+	}
+
+	// the arguments and the result are flattened schema values, which always encode,
+	// maps are encoded with sorted keys, so the hash doesn't depend on their order
+	b, _ := json.Marshal([]interface{}{args, result})
+
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
@@ -0,0 +1,689 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// fakeArdoq is a minimal in memory implementation of the Ardoq REST API, it lets the
+// create, read, update and delete functions run without an Ardoq organization.
+// Objects are stored as the flat JSON objects Ardoq returns, a PATCH merges the
+// request into the object and a null value clears the attribute, like Ardoq does.
+// Components and references get the audit attributes Ardoq sets, the counts of children and
+// references of components are computed when they are returned.
+// Error responses can be injected with fail, to test how they are handled
+type fakeArdoq struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	objects  map[string]map[string]map[string]interface{}
+	requests []fakeRequest
+	failures []fakeFailure
+	lastID   int
+	clock    int
+}
+
+// fakeFailure is an injected error response, it is returned for the next times requests
+// with the method and path, i.e. "GET" and "component/<id>". An empty method matches any method
+type fakeFailure struct {
+	method string
+	path   string
+	code   int
+	header http.Header
+	skip   int
+	times  int
+}
+
+// fakeRequest is a request received by fakeArdoq, Body is nil for requests without body
+type fakeRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   map[string]interface{}
+}
+
+func newFakeArdoq(t *testing.T) *fakeArdoq {
+	t.Helper()
+
+	f := &fakeArdoq{
+		objects: make(map[string]map[string]map[string]interface{}),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// put stores an object of the given kind, i.e. "workspace", and returns its id
+func (f *fakeArdoq) put(kind string, object map[string]interface{}) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.store(kind, object)
+}
+
+// get returns a copy of an object, or nil if it doesn't exist
+func (f *fakeArdoq) get(kind, id string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	object, ok := f.objects[kind][id]
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		result[k] = v
+	}
+	return result
+}
+
+// delete removes an object, as if it was deleted outside Terraform
+func (f *fakeArdoq) delete(kind, id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.objects[kind], id)
+}
+
+// fail makes the next times requests with the method and path return an error with the status code
+func (f *fakeArdoq) fail(method, path string, code, times int) {
+	f.failWithHeader(method, path, code, times, nil)
+}
+
+// failWithHeader is fail, the error response has the headers set, i.e. Retry-After
+func (f *fakeArdoq) failWithHeader(method, path string, code, times int, header http.Header) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, fakeFailure{method: method, path: path, code: code, header: header, times: times})
+}
+
+// failAfter is fail, the first skip requests with the method and path succeed
+func (f *fakeArdoq) failAfter(method, path string, skip, code, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, fakeFailure{method: method, path: path, code: code, skip: skip, times: times})
+}
+
+// requestCount returns the number of requests received with the method and path
+func (f *fakeArdoq) requestCount(method, path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, r := range f.requests {
+		if r.Method == method && r.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+// lastRequest returns the last request with the given method and path, i.e. "PATCH" and "component/<id>"
+func (f *fakeArdoq) lastRequest(t *testing.T, method, path string) fakeRequest {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := len(f.requests) - 1; i >= 0; i-- {
+		if r := f.requests[i]; r.Method == method && r.Path == path {
+			return r
+		}
+	}
+
+	t.Fatalf("no %s %s request received", method, path)
+	return fakeRequest{}
+}
+
+func (f *fakeArdoq) store(kind string, object map[string]interface{}) string {
+	id, _ := object["_id"].(string)
+	if id == "" {
+		f.lastID++
+		id = fmt.Sprintf("%s%06d", kind, f.lastID)
+		object["_id"] = id
+	}
+
+	if f.objects[kind] == nil {
+		f.objects[kind] = make(map[string]map[string]interface{})
+	}
+	f.objects[kind][id] = object
+
+	return id
+}
+
+func (f *fakeArdoq) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]interface{}
+	if r.Body != nil {
+		// requests without a body decode to nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/")
+	f.requests = append(f.requests, fakeRequest{Method: r.Method, Path: path, Query: r.URL.Query(), Body: body})
+
+	for i := range f.failures {
+		failure := &f.failures[i]
+		if failure.times > 0 && (failure.method == "" || failure.method == r.Method) && failure.path == path {
+			if failure.skip > 0 {
+				failure.skip--
+				continue
+			}
+			failure.times--
+			for k, v := range failure.header {
+				w.Header()[k] = v
+			}
+			writeFakeError(w, failure.code, fmt.Sprintf("injected %d for %s %s", failure.code, r.Method, path))
+			return
+		}
+	}
+
+	switch path {
+	case "component/search":
+		f.search(w, "component", map[string]string{
+			"rootWorkspace": r.URL.Query().Get("workspace"),
+			"name":          r.URL.Query().Get("name"),
+		})
+		return
+	case "workspace/search":
+		// Ardoq returns the workspace with the name, instead of a list
+		for _, id := range f.ids("workspace") {
+			if workspace := f.objects["workspace"][id]; workspace["name"] == r.URL.Query().Get("name") {
+				writeFakeJSON(w, http.StatusOK, workspace)
+				return
+			}
+		}
+		writeFakeError(w, http.StatusNotFound, "workspace not found")
+		return
+	case "v2/batch":
+		f.batch(w, body)
+		return
+	}
+
+	// the references of a workspace, workspace/<id>/reference
+	if parts := strings.Split(path, "/"); len(parts) == 3 && parts[0] == "workspace" && parts[2] == "reference" {
+		if _, ok := f.objects["workspace"][parts[1]]; !ok {
+			writeFakeError(w, http.StatusNotFound, fmt.Sprintf("workspace %s not found", parts[1]))
+			return
+		}
+		f.search(w, "reference", map[string]string{"rootWorkspace": parts[1]})
+		return
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	kind := parts[0]
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			f.list(w, kind)
+		case http.MethodPost:
+			object := make(map[string]interface{})
+			for k, v := range body {
+				if v != nil {
+					object[k] = v
+				}
+			}
+			object["_version"] = 1
+			f.store(kind, object)
+			f.setComponentType(kind, object)
+			f.touch(kind, object, true)
+			writeFakeJSON(w, http.StatusCreated, f.withCounts(kind, object))
+		default:
+			writeFakeError(w, http.StatusMethodNotAllowed, r.Method+" is not allowed")
+		}
+		return
+	}
+
+	id := parts[1]
+	object, ok := f.objects[kind][id]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, fmt.Sprintf("%s %s not found", kind, id))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, f.withCounts(kind, object))
+	case http.MethodPatch, http.MethodPut:
+		// an update with a version is only applied to that version, like Ardoq does
+		if expected, ok := body["_version"]; ok {
+			if fmt.Sprint(expected) != fmt.Sprint(object["_version"]) {
+				writeFakeError(w, http.StatusConflict, fmt.Sprintf("%s %s has version %v, not %v", kind, id, object["_version"], expected))
+				return
+			}
+		}
+		for k, v := range body {
+			if k == "_version" {
+				continue
+			}
+			if v == nil {
+				delete(object, k)
+			} else {
+				object[k] = v
+			}
+		}
+		version, _ := object["_version"].(int)
+		object["_version"] = version + 1
+		f.setComponentType(kind, object)
+		f.touch(kind, object, false)
+		writeFakeJSON(w, http.StatusOK, f.withCounts(kind, object))
+	case http.MethodDelete:
+		delete(f.objects[kind], id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, r.Method+" is not allowed")
+	}
+}
+
+// batch applies the component operations of a batch, custom fields are sent as
+// "customFields" and stored flat, like the v1 API returns them
+func (f *fakeArdoq) batch(w http.ResponseWriter, body map[string]interface{}) {
+	components, _ := body["components"].(map[string]interface{})
+	operations := func(name string) []map[string]interface{} {
+		list, _ := components[name].([]interface{})
+		result := make([]map[string]interface{}, 0, len(list))
+		for _, operation := range list {
+			operation, _ := operation.(map[string]interface{})
+			result = append(result, operation)
+		}
+		return result
+	}
+	flatten := func(object, body map[string]interface{}) {
+		fields := make(map[string]interface{})
+		customFields, _ := body["customFields"].(map[string]interface{})
+		for k, v := range customFields {
+			fields[k] = v
+		}
+		for k, v := range body {
+			if k != "customFields" {
+				fields[k] = v
+			}
+		}
+		for k, v := range fields {
+			if v == nil {
+				delete(object, k)
+			} else {
+				object[k] = v
+			}
+		}
+	}
+
+	// Ardoq applies all operations of a batch or none of them, so everything is checked first
+	for _, operation := range append(operations("update"), operations("delete")...) {
+		if _, ok := f.objects["component"][fmt.Sprint(operation["id"])]; !ok {
+			writeFakeError(w, http.StatusNotFound, fmt.Sprintf("component %v not found", operation["id"]))
+			return
+		}
+	}
+
+	created := make([]map[string]interface{}, 0)
+	for _, operation := range operations("create") {
+		object := map[string]interface{}{"_version": 1}
+		body, _ := operation["body"].(map[string]interface{})
+		flatten(object, body)
+		id := f.store("component", object)
+		f.setComponentType("component", object)
+		f.touch("component", object, true)
+		created = append(created, map[string]interface{}{"batchId": operation["batchId"], "id": id})
+	}
+
+	updated := make([]map[string]interface{}, 0)
+	for _, operation := range operations("update") {
+		object := f.objects["component"][fmt.Sprint(operation["id"])]
+		body, _ := operation["body"].(map[string]interface{})
+		flatten(object, body)
+		version, _ := object["_version"].(int)
+		object["_version"] = version + 1
+		f.setComponentType("component", object)
+		f.touch("component", object, false)
+		updated = append(updated, map[string]interface{}{"id": operation["id"]})
+	}
+
+	deleted := make([]map[string]interface{}, 0)
+	for _, operation := range operations("delete") {
+		delete(f.objects["component"], fmt.Sprint(operation["id"]))
+		deleted = append(deleted, map[string]interface{}{"id": operation["id"]})
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"components": map[string]interface{}{
+			"created": created,
+			"updated": updated,
+			"deleted": deleted,
+		},
+	})
+}
+
+func (f *fakeArdoq) list(w http.ResponseWriter, kind string) {
+	f.search(w, kind, nil)
+}
+
+// search returns the objects of a kind which have the attributes, empty values match any value
+func (f *fakeArdoq) search(w http.ResponseWriter, kind string, attributes map[string]string) {
+	result := make([]map[string]interface{}, 0)
+
+objects:
+	for _, id := range f.ids(kind) {
+		object := f.objects[kind][id]
+		for k, v := range attributes {
+			if v != "" && object[k] != v {
+				continue objects
+			}
+		}
+		result = append(result, f.withCounts(kind, object))
+	}
+
+	writeFakeJSON(w, http.StatusOK, result)
+}
+
+// touch sets the audit attributes of a created or updated component or reference,
+// every change is a minute after the previous one
+func (f *fakeArdoq) touch(kind string, object map[string]interface{}, created bool) {
+	if kind != "component" && kind != "reference" {
+		return
+	}
+
+	f.clock++
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(f.clock) * time.Minute).Format(time.RFC3339)
+
+	if created {
+		object["created"] = now
+		object["created-by"] = "user1"
+		object["createdByName"] = "Terraform"
+		object["createdByEmail"] = "terraform@example.com"
+		if kind == "component" {
+			object["component-key"] = fmt.Sprintf("TF-%d", f.lastID)
+			object["ardoq"] = map[string]interface{}{"persistent": map[string]interface{}{"ordering": f.lastID}}
+		}
+	}
+	object["last-updated"] = now
+	object["last-modified-by"] = "user1"
+	object["lastModifiedByName"] = "Terraform"
+	object["lastModifiedByEmail"] = "terraform@example.com"
+}
+
+// withCounts returns a copy of a component with its children and the counts of its
+// references, which Ardoq computes. Other objects are returned unchanged
+func (f *fakeArdoq) withCounts(kind string, object map[string]interface{}) map[string]interface{} {
+	if kind != "component" {
+		return object
+	}
+
+	result := make(map[string]interface{}, len(object)+1)
+	for k, v := range object {
+		result[k] = v
+	}
+
+	children := make([]interface{}, 0)
+	for _, id := range f.ids("component") {
+		if f.objects["component"][id]["parent"] == object["_id"] {
+			children = append(children, id)
+		}
+	}
+	result["children"] = children
+
+	incoming, outgoing := 0, 0
+	for _, reference := range f.objects["reference"] {
+		if reference["target"] == object["_id"] {
+			incoming++
+		}
+		if reference["source"] == object["_id"] {
+			outgoing++
+		}
+	}
+
+	ardoq := map[string]interface{}{}
+	if stored, ok := object["ardoq"].(map[string]interface{}); ok {
+		for k, v := range stored {
+			ardoq[k] = v
+		}
+	}
+	ardoq["incomingReferenceCount"] = incoming
+	ardoq["outgoingReferenceCount"] = outgoing
+	result["ardoq"] = ardoq
+
+	return result
+}
+
+// setComponentType sets the name of the type of a component, Ardoq returns it as "type"
+func (f *fakeArdoq) setComponentType(kind string, object map[string]interface{}) {
+	if kind != "component" {
+		return
+	}
+
+	workspace := f.objects["workspace"][fmt.Sprint(object["rootWorkspace"])]
+	model := f.objects["model"][fmt.Sprint(workspace["componentModel"])]
+
+	var find func(types interface{}) interface{}
+	find = func(types interface{}) interface{} {
+		typesMap, _ := types.(map[string]interface{})
+		for id, componentType := range typesMap {
+			componentType, _ := componentType.(map[string]interface{})
+			if id == object["typeId"] {
+				return componentType["name"]
+			}
+			if name := find(componentType["children"]); name != nil {
+				return name
+			}
+		}
+		return nil
+	}
+
+	if name := find(model["root"]); name != nil {
+		object["type"] = name
+	} else {
+		delete(object, "type")
+	}
+}
+
+// ids returns the ids of the objects of a kind in a stable order
+func (f *fakeArdoq) ids(kind string) []string {
+	ids := make([]string, 0, len(f.objects[kind]))
+	for id := range f.objects[kind] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func writeFakeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, code int, message string) {
+	writeFakeJSON(w, code, map[string]interface{}{"message": message})
+}
+
+// testUnknownValue is how the SDK represents a configuration value which is unknown during plan,
+// i.e. the id of a resource which is created in the same apply
+const testUnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+// testFakeMeta configures the provider against the fake API and returns its meta object,
+// retries don't wait so tests with injected errors stay fast
+func testFakeMeta(t *testing.T, f *fakeArdoq) interface{} {
+	t.Helper()
+
+	return testFakeMetaWithConfig(t, f, nil)
+}
+
+// testFakeMetaWithConfig is testFakeMeta with additional provider settings
+func testFakeMetaWithConfig(t *testing.T, f *fakeArdoq, config map[string]interface{}) interface{} {
+	t.Helper()
+
+	raw := map[string]interface{}{
+		"apikey":         "test",
+		"baseuri":        f.URL + "/api/",
+		"retry_min_wait": 0,
+		"retry_max_wait": 0,
+	}
+	for k, v := range config {
+		raw[k] = v
+	}
+
+	p := New("test")()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	if diags.HasError() {
+		t.Fatalf("could not configure provider: %v", diags)
+	}
+
+	return p.Meta()
+}
+
+// testApplyResource plans and applies a configuration the same way Terraform does,
+// a nil state creates the resource. It returns the new state
+func testApplyResource(t *testing.T, r *schema.Resource, state *terraform.InstanceState, config map[string]interface{}, meta interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	newState, err := testApplyResourceErr(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newState
+}
+
+// testApplyResourceErr is testApplyResource for configurations which are expected to fail
+func testApplyResourceErr(r *schema.Resource, state *terraform.InstanceState, config map[string]interface{}, meta interface{}) (*terraform.InstanceState, error) {
+	ctx := context.Background()
+
+	diff, err := r.Diff(ctx, state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		return state, fmt.Errorf("plan failed: %w", err)
+	}
+	if diff == nil {
+		return state, nil
+	}
+
+	newState, diags := r.Apply(ctx, state, diff, meta)
+	if diags.HasError() {
+		return newState, fmt.Errorf("apply failed: %v", diags)
+	}
+
+	return newState, nil
+}
+
+// testRefreshResource reads the resource, like Terraform does before every plan.
+// It returns nil if the resource is gone
+func testRefreshResource(t *testing.T, r *schema.Resource, state *terraform.InstanceState, meta interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	newState, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	if diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+
+	return newState
+}
+
+// testImportResourceVerify imports the resource by its id and checks the imported
+// state matches state, except for the ignored attributes, like ImportStateVerify
+func testImportResourceVerify(t *testing.T, r *schema.Resource, state *terraform.InstanceState, meta interface{}, ignore ...string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	data := r.Data(&terraform.InstanceState{ID: state.ID})
+	imported, err := r.Importer.StateContext(ctx, data, meta)
+	if err != nil {
+		t.Fatalf("import failed: %s", err)
+	}
+	if len(imported) != 1 {
+		t.Fatalf("import returned %d resources, want 1", len(imported))
+	}
+
+	importedState := testRefreshResource(t, r, imported[0].State(), meta)
+	if importedState == nil {
+		t.Fatalf("imported resource %s not found", state.ID)
+	}
+
+	skip := func(key string) bool {
+		for _, prefix := range append(ignore, "%", "id") {
+			if key == prefix || strings.HasPrefix(key, prefix+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for key, want := range state.Attributes {
+		if got := importedState.Attributes[key]; !skip(key) && got != want {
+			t.Errorf("imported %s = %q, want %q", key, got, want)
+		}
+	}
+	for key, got := range importedState.Attributes {
+		if _, ok := state.Attributes[key]; !ok && !skip(key) && got != "" && got != "0" {
+			t.Errorf("imported %s = %q, which is not in the state", key, got)
+		}
+	}
+}
+
+// testDestroyResource deletes the resource, like terraform destroy
+func testDestroyResource(t *testing.T, r *schema.Resource, state *terraform.InstanceState, meta interface{}) {
+	t.Helper()
+
+	if _, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{Destroy: true}, meta); diags.HasError() {
+		t.Fatalf("destroy failed: %v", diags)
+	}
+}
+
+// testReadDataSource reads a data source with the configuration and returns its state
+func testReadDataSource(t *testing.T, r *schema.Resource, config map[string]interface{}, meta interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	state, err := testReadDataSourceErr(r, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return state
+}
+
+// testReadDataSourceErr is testReadDataSource for configurations which are expected to fail
+func testReadDataSourceErr(r *schema.Resource, config map[string]interface{}, meta interface{}) (*terraform.InstanceState, error) {
+	ctx := context.Background()
+
+	diff, err := r.Diff(ctx, nil, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		return nil, fmt.Errorf("plan failed: %w", err)
+	}
+
+	state, diags := r.ReadDataApply(ctx, diff, meta)
+	if diags.HasError() {
+		return nil, fmt.Errorf("read failed: %v", diags)
+	}
+
+	return state, nil
+}
+
+// testCheckAttributes compares the attributes of a state with the expected values
+func testCheckAttributes(t *testing.T, state *terraform.InstanceState, want map[string]string) {
+	t.Helper()
+
+	for key, value := range want {
+		if got := state.Attributes[key]; got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+// modelFields returns the fields of a model, keyed by their name
+func modelFields(ctx context.Context, c *apiClient, model string) (map[string]ardoq.Field, error) {
+	fields, err := c.Fields().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]ardoq.Field)
+	for _, field := range *fields {
+		if field.Model != model {
+			continue
+		}
+		result[field.Name] = field
+	}
+
+	return result, nil
+}
+
+// fieldAppliesToComponentType returns whether a field can be used on components of a type.
+// If typeID is empty, the type is not known yet, and the field has to apply to any component type,
+// fields which only apply to reference types don't
+func fieldAppliesToComponentType(field ardoq.Field, typeID string) bool {
+	if typeID == "" {
+		return field.Global || len(field.ComponentType) > 0
+	}
+
+	return field.Global || containsString(field.ComponentType, typeID)
+}
+
+// validateFields checks the custom fields of a component against the fields of the model, all problems are
+// returned in one error
+func validateFields(values map[string]interface{}, fields map[string]ardoq.Field, typeID string) error {
+	var problems []string
+
+	for key, value := range values {
+		field, ok := fields[key]
+		if !ok || !fieldAppliesToComponentType(field, typeID) {
+			var names []string
+			for name, field := range fields {
+				if fieldAppliesToComponentType(field, typeID) {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+
+			problem := "unknown field"
+			if ok {
+				problem = "the field doesn't apply to the component type"
+			}
+			problems = append(problems, fmt.Sprintf("fields[%q]: %s, valid fields are %q", key, problem, names))
+			continue
+		}
+
+		if err := validateFieldValue(field, value); err != nil {
+			problems = append(problems, fmt.Sprintf("fields[%q]: %s", key, err))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return fmt.Errorf("invalid custom fields:\n%s", strings.Join(problems, "\n"))
+}
+
+// validateFieldValue checks a value against the type of the field, values from a
+// map of strings are parsed, values from JSON are checked for their type
+func validateFieldValue(field ardoq.Field, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	s, isString := value.(string)
+
+	switch field.Type {
+	case fieldTypeNumber:
+		switch value.(type) {
+		case float64, json.Number:
+			return nil
+		}
+		if _, err := strconv.ParseFloat(s, 64); !isString || err != nil {
+			return fmt.Errorf("%v is not a number", value)
+		}
+	case fieldTypeCheckbox:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+		if _, err := strconv.ParseBool(s); !isString || err != nil {
+			return fmt.Errorf("%v is not a boolean", value)
+		}
+	case fieldTypeDateTime:
+		if !isString || !isDateTime(s) {
+			return fmt.Errorf("%v is not a date, use the format 2006-01-02 or 2006-01-02T15:04:05Z", value)
+		}
+	case fieldTypeURL:
+		if u, err := url.ParseRequestURI(s); !isString || err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%v is not a URL", value)
+		}
+	case fieldTypeEmail:
+		if _, err := mail.ParseAddress(s); !isString || err != nil {
+			return fmt.Errorf("%v is not an email address", value)
+		}
+	case fieldTypeList:
+		if options := fieldOptions(&field); !isString || !containsString(options, s) {
+			return fmt.Errorf("%v is not one of the options %q", value, options)
+		}
+	case fieldTypeSelectMultipleList:
+		var selected []string
+		switch v := value.(type) {
+		case string:
+			// the same comma separated format as convertFields returns
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					selected = append(selected, item)
+				}
+			}
+		case []interface{}:
+			for _, item := range v {
+				selected = append(selected, fmt.Sprint(item))
+			}
+		default:
+			return fmt.Errorf("%v is not a list", value)
+		}
+
+		options := fieldOptions(&field)
+		for _, item := range selected {
+			if !containsString(options, item) {
+				return fmt.Errorf("%q is not one of the options %q", item, options)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isDateTime(s string) bool {
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
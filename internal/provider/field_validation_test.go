@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+func TestValidateFieldValue(t *testing.T) {
+	t.Parallel()
+
+	options := map[string]interface{}{"options": []interface{}{"Active", "Retired"}}
+
+	cases := []struct {
+		fieldType string
+		value     interface{}
+		valid     bool
+	}{
+		{fieldTypeText, "anything", true},
+		{fieldTypeNumber, "42.5", true},
+		{fieldTypeNumber, 42.5, true},
+		{fieldTypeNumber, "forty two", false},
+		{fieldTypeCheckbox, "true", true},
+		{fieldTypeCheckbox, "yes", false},
+		{fieldTypeDateTime, "2021-08-01", true},
+		{fieldTypeDateTime, "2021-08-01T12:00:00Z", true},
+		{fieldTypeDateTime, "01-08-2021", false},
+		{fieldTypeURL, "https://www.ardoq.com", true},
+		{fieldTypeURL, "www.ardoq.com", false},
+		{fieldTypeEmail, "someone@example.com", true},
+		{fieldTypeEmail, "someone", false},
+		{fieldTypeList, "Active", true},
+		{fieldTypeList, "Unknown", false},
+		{fieldTypeSelectMultipleList, "Active, Retired", true},
+		{fieldTypeSelectMultipleList, []interface{}{"Active"}, true},
+		{fieldTypeSelectMultipleList, "Active, Unknown", false},
+	}
+
+	for _, tc := range cases {
+		field := ardoq.Field{Name: "f", Type: tc.fieldType, Fields: options}
+		err := validateFieldValue(field, tc.value)
+		if tc.valid && err != nil {
+			t.Errorf("%s %v: expected valid, got %s", tc.fieldType, tc.value, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s %v: expected an error", tc.fieldType, tc.value)
+		}
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	t.Parallel()
+
+	fields := map[string]ardoq.Field{
+		"cost":    {Name: "cost", Type: fieldTypeNumber, Global: true},
+		"runtime": {Name: "runtime", Type: fieldTypeText, ComponentType: []string{"p1"}},
+		"latency": {Name: "latency", Type: fieldTypeNumber, GlobalRef: true},
+	}
+
+	err := validateFields(map[string]interface{}{"cost": "a lot", "owner": "me", "latency": "10"}, fields, "")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, key := range []string{
+		`fields["cost"]`,
+		`fields["latency"]: the field doesn't apply to the component type, valid fields are ["cost" "runtime"]`,
+		`fields["owner"]: unknown field, valid fields are ["cost" "runtime"]`,
+	} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected %q in error: %s", key, err)
+		}
+	}
+
+	if err := validateFields(map[string]interface{}{"cost": "12", "runtime": "go"}, fields, "p1"); err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+	if err := validateFields(map[string]interface{}{"runtime": "go"}, fields, "p2"); err == nil {
+		t.Errorf("expected an error for a field of another type")
+	}
+}
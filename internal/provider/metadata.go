@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// auditAttributesOnUpdate are the audit attributes Ardoq changes on every update
+var auditAttributesOnUpdate = []string{"last_updated", "last_modified_by", "last_modified_by_email", "last_modified_by_name", "version"}
+
+// addAuditFieldsToSchema adds the computed attributes Ardoq keeps about who created
+// and changed an object, they are the same for components and references
+func addAuditFieldsToSchema(s map[string]*schema.Schema) {
+	s["created"] = &schema.Schema{
+		Description: "Time the object was created",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+	s["created_by"] = &schema.Schema{
+		Description: "Id of the user who created the object",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+	s["created_by_email"] = &schema.Schema{
+		Description: "Email of the user who created the object",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+	s["created_by_name"] = &schema.Schema{
+		Description: "Name of the user who created the object",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+	s["last_updated"] = &schema.Schema{
+		Description: "Time the object was last updated",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+	s["last_modified_by"] = &schema.Schema{
+		Description: "Id of the user who last updated the object",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+	s["last_modified_by_email"] = &schema.Schema{
+		Description: "Email of the user who last updated the object",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+	s["last_modified_by_name"] = &schema.Schema{
+		Description: "Name of the user who last updated the object",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+	s["version"] = &schema.Schema{
+		Description: "Version of the object, it increases with every update",
+		Type:        schema.TypeInt,
+		Computed:    true,
+	}
+}
+
+// resourceAuditComputedOnChange marks the audit attributes Ardoq changes on every update as
+// unknown when the plan updates the resource, otherwise the plan shows their old values
+func resourceAuditComputedOnChange(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || len(d.GetChangedKeysPrefix("")) == 0 {
+		return nil
+	}
+
+	for _, key := range auditAttributesOnUpdate {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expectVersion adds the version of the object in the state to the fields of an update, when
+// optimistic concurrency is enabled. Ardoq rejects the update with 409 Conflict when the object
+// has another version, because it was changed since it was read
+func expectVersion(c *apiClient, d *schema.ResourceData, fields map[string]interface{}) {
+	if !c.optimisticConcurrency {
+		return
+	}
+
+	// the new version is unknown until the update is applied, the old one is the version the plan was made with
+	version, _ := d.GetChange("version")
+	if version.(int) > 0 {
+		fields["_version"] = version.(int)
+	}
+}
+
+// updateError returns the diagnostics for a failed update of a component or reference,
+// a version conflict means the object was changed outside Terraform
+func updateError(c *apiClient, kind string, err error) diag.Diagnostics {
+	if !c.optimisticConcurrency || !isAPIErrorWithCode(err, http.StatusConflict) {
+		return diag.FromErr(err)
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s modified outside Terraform since plan; re-run plan", kind),
+		Detail: fmt.Sprintf("Ardoq has a newer version of the %s than the one the plan was made with, so the update was rejected "+
+			"instead of overwriting the changes. Set optimistic_concurrency to false in the provider configuration to overwrite them anyway. %s", kind, err),
+	}}
+}
+
+// flattenPersistent converts the persistent data Ardoq keeps about an object, i.e. its
+// ordering, to strings so it fits in a TypeMap of TypeString
+func flattenPersistent(persistent interface{}) map[string]string {
+	values, ok := persistent.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	return convertFields(values)
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+// workspaceModel returns the model a workspace is based on
+func workspaceModel(ctx context.Context, c *apiClient, workspaceID string) (*ardoq.Model, error) {
+	workspace, err := c.Workspaces().Get(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("could not read workspace %s: %w", workspaceID, err)
+	}
+
+	model, err := c.Models().Read(ctx, workspace.ComponentModel)
+	if err != nil {
+		return nil, fmt.Errorf("could not read model %s of workspace %s: %w", workspace.ComponentModel, workspaceID, err)
+	}
+
+	return model, nil
+}
+
+// componentTypeID returns the id of the component type with the given name
+func componentTypeID(model *ardoq.Model, name string) (string, error) {
+	types := model.GetComponentTypeID()
+
+	id, ok := types[name]
+	if !ok {
+		return "", fmt.Errorf("component type %q not found in model %q, valid type names are %q", name, model.Name, sortedKeys(types))
+	}
+
+	return id, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// referenceTypeID returns the id of the reference type with the given name
+func referenceTypeID(model *ardoq.Model, name string) (int, error) {
+	types := model.GetReferenceTypes()
+
+	id, ok := types[name]
+	if !ok {
+		return 0, fmt.Errorf("reference type %q not found in model %q, valid type names are %q", name, model.Name, sortedKeys(types))
+	}
+
+	return strconv.Atoi(id)
+}
+
+// referenceTypeName returns the name of the reference type with the given id
+func referenceTypeName(model *ardoq.Model, id int) (string, error) {
+	types := model.GetReferenceTypes()
+
+	for name, typeID := range types {
+		if typeID == strconv.Itoa(id) {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("reference type %d not found in model %q, valid types are %q", id, model.Name, types)
+}
@@ -3,11 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	ardoq "github.com/mories76/ardoq-client-go/pkg"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func init() {
@@ -53,22 +55,73 @@ func New(version string) func() *schema.Provider {
 					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("ARDOQ_ORG", nil),
 				},
+				"max_retries": {
+					Description: "Maximum number of times a request is retried when Ardoq responds with 429 Too Many Requests, " +
+						"or with 502, 503 or 504 for requests which are safe to repeat. Set to 0 to disable retries.",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      5,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"retry_min_wait": {
+					Description: "Seconds to wait before the first retry, the wait time doubles for every next retry. " +
+						"The `Retry-After` header of the response takes precedence.",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"retry_max_wait": {
+					Description:  "Maximum seconds to wait between retries, a longer `Retry-After` header of the response is limited to it as well.",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      30,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"requests_per_second": {
+					Description: "Maximum number of requests per second sent to Ardoq, shared by all resources and data sources " +
+						"of the provider configuration. Set to 0 for no limit.",
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.FloatAtLeast(0),
+				},
+				"max_concurrent_requests": {
+					Description: "Maximum number of requests sent to Ardoq at the same time, shared by all resources and data sources " +
+						"of the provider configuration. Set to 0 for no limit.",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"optimistic_concurrency": {
+					Description: "Send the version of a component or reference with every update, so Ardoq rejects the update when the object " +
+						"was changed outside Terraform since the plan, instead of overwriting the change. Set to false to always overwrite.",
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
 			},
 			DataSourcesMap: map[string]*schema.Resource{
-				"ardoq_component":  dataSourceArdoqComponent(),
-				"ardoq_components": dataSourceArdoqComponents(),
-				"ardoq_field":      dataSourceArdoqField(),
-				"ardoq_fields":     dataSourceArdoqFields(),
-				"ardoq_model":      dataSourceArdoqModel(),
-				"ardoq_models":     dataSourceArdoqModels(),
-				"ardoq_reference":  dataSourceArdoqReference(),
-				"ardoq_references": dataSourceArdoqReferences(),
-				"ardoq_workspace":  dataSourceArdoqWorkspace(),
-				"ardoq_workspaces": dataSourceArdoqWorkspaces(),
+				"ardoq_component":      dataSourceArdoqComponent(),
+				"ardoq_components":     dataSourceArdoqComponents(),
+				"ardoq_field":          dataSourceArdoqField(),
+				"ardoq_fields":         dataSourceArdoqFields(),
+				"ardoq_model":          dataSourceArdoqModel(),
+				"ardoq_models":         dataSourceArdoqModels(),
+				"ardoq_reference":      dataSourceArdoqReference(),
+				"ardoq_references":     dataSourceArdoqReferences(),
+				"ardoq_tabular_source": dataSourceArdoqTabularSource(),
+				"ardoq_workspace":      dataSourceArdoqWorkspace(),
+				"ardoq_workspaces":     dataSourceArdoqWorkspaces(),
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				"ardoq_component": resourceArdoqComponent(),
-				"ardoq_reference": resourceArdoqReference(),
+				"ardoq_component":     resourceArdoqComponent(),
+				"ardoq_component_set": resourceArdoqComponentSet(),
+				"ardoq_field":         resourceArdoqField(),
+				"ardoq_model":         resourceArdoqModel(),
+				"ardoq_reference":     resourceArdoqReference(),
+				"ardoq_workspace":     resourceArdoqWorkspace(),
 			},
 			// ConfigureContextFunc: configure,
 		}
@@ -113,11 +166,22 @@ func configure(version string, p *schema.Provider) func(context.Context, *schema
 			org = v.(string)
 		}
 
+		minWait := time.Duration(d.Get("retry_min_wait").(int)) * time.Second
+		maxWait := time.Duration(d.Get("retry_max_wait").(int)) * time.Second
+		if minWait > maxWait {
+			return nil, diag.Errorf("retry_min_wait (%s) can't be more than retry_max_wait (%s)", minWait, maxWait)
+		}
+
+		// every retry is throttled as well, so retries don't add to the rate limiting
+		throttle := newThrottleTransport(http.DefaultTransport, d.Get("requests_per_second").(float64), d.Get("max_concurrent_requests").(int))
+		transport := newRetryTransport(throttle, d.Get("max_retries").(int), minWait, maxWait)
+
 		// create new client
-		c, err := ardoq.NewRestClient(baseuri, apikey, org, version)
+		c, err := newAPIClient(baseuri, apikey, org, version, transport)
 		if err != nil {
 			return nil, diag.FromErr(err)
 		}
+		c.optimisticConcurrency = d.Get("optimistic_concurrency").(bool)
 
 		return c, diags
 	}
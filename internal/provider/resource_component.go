@@ -2,19 +2,27 @@ package provider
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	ardoq "github.com/mories76/ardoq-client-go/pkg"
 )
 
 func resourceArdoqComponent() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		Description:   "`ardoq_component` resource lets you create a component",
 		CreateContext: resourceArdoqComponentCreate,
 		ReadContext:   resourceArdoqComponentRead,
 		UpdateContext: resourceArdoqComponentUpdate,
 		DeleteContext: resourceArdoqComponentDelete,
+		CustomizeDiff: customdiff.Sequence(
+			resourceArdoqComponentResolveTypeName,
+			resourceArdoqComponentValidateFields,
+			resourceArdoqComponentValidateMove,
+			resourceAuditComputedOnChange,
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -35,39 +43,76 @@ func resourceArdoqComponent() *schema.Resource {
 				Computed:    true,
 			},
 			"parent": {
-				Description: "Id of the component's parent",
-				Type:        schema.TypeString,
-				Optional:    true,
-				Computed:    true,
+				Description: "Id of the component's parent, it has to be in the same workspace. Changing it moves the component, " +
+					"removing it moves the component to the top level of the workspace",
+				Type:     schema.TypeString,
+				Optional: true,
 			},
 			"type_id": {
-				Description: "Id of the component's type",
-				Type:        schema.TypeString,
-				Optional:    true,
-				Computed:    true,
+				Description:   "Id of the component's type",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"type_name"},
+			},
+			"type_name": {
+				Description: "Name of the component's type, the id of the type is looked up in the model of the workspace. " +
+					"Unlike `type_id` it's the same in every Ardoq organization using the same model",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"type_id"},
 			},
 			"root_workspace": {
-				Description: "Id of the workspace the component belongs to",
+				Description: "Id of the workspace the component belongs to. Ardoq can't move a component to another workspace, " +
+					"so changing it replaces the component. Ardoq deletes the references of the old component, " +
+					"only the references managed by Terraform are created again",
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"fields":      customFieldsSchema(),
+			"fields_json": fieldsJSONSchema(),
+			"component_key": {
+				Description: "Key of the component, unique in the organization and shown in the Ardoq app",
 				Type:        schema.TypeString,
-				Required:    true,
+				Computed:    true,
 			},
-			"fields": {
-				Description: "All custom fields from the model end up here",
+			"ardoq_persistent": {
+				Description: "Data Ardoq keeps about the component, i.e. its `ordering`",
 				Type:        schema.TypeMap,
+				Computed:    true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
-				Optional: true,
+			},
+			"children_count": {
+				Description: "Number of children of the component",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"incoming_reference_count": {
+				Description: "Number of references to the component",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"outgoing_reference_count": {
+				Description: "Number of references from the component",
+				Type:        schema.TypeInt,
+				Computed:    true,
 			},
 		},
 	}
+	addAuditFieldsToSchema(r.Schema)
+
+	return r
 }
 
 func resourceArdoqComponentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Warning or errors can be collected in a slice type
 	// var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 
 	// get all required fields
 	req := ardoq.ComponentRequest{
@@ -87,21 +132,23 @@ func resourceArdoqComponentCreate(ctx context.Context, d *schema.ResourceData, m
 
 	if v, ok := d.GetOk("type_id"); ok {
 		req.TypeID = v.(string)
+	} else if v, ok := d.GetOk("type_name"); ok {
+		// the type could not be resolved during plan, because the workspace didn't exist yet
+		model, err := workspaceModel(ctx, c, req.RootWorkspace)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if req.TypeID, err = componentTypeID(model, v.(string)); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
-	// check if custom fields are specified by checking len of the schema field "fields"
-	// if so, loop map and add each field to the request
-	if len(d.Get("fields").(map[string]interface{})) > 0 {
-		//create temporary map
-		fields := make(map[string]interface{})
-
-		// foreach key and value append to temporary fields maps
-		for k, v := range d.Get("fields").(map[string]interface{}) {
-			fields[k] = v.(string)
-		}
-		// assign temporary fields map to req
-		req.Fields = fields
+	// custom fields are either set as map of strings in "fields" or as JSON in "fields_json"
+	fields, err := expandCustomFields(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	req.Fields = fields
 
 	component, err := c.Components().Create(ctx, req)
 	if err != nil {
@@ -117,7 +164,7 @@ func resourceArdoqComponentRead(ctx context.Context, d *schema.ResourceData, m i
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 
 	component, err := c.Components().Read(ctx, d.Id())
 	if err != nil {
@@ -127,12 +174,20 @@ func resourceArdoqComponentRead(ctx context.Context, d *schema.ResourceData, m i
 
 	cmp := flattenComponent(component)
 
+	// the custom fields are set on the attribute used in the configuration by setCustomFields
+	delete(cmp, "fields")
+	delete(cmp, "fields_json")
+
 	for key, val := range cmp {
 		if err := d.Set(key, val); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
+	if err := setCustomFields(d, component.Fields); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return diags
 }
 
@@ -140,7 +195,7 @@ func resourceArdoqComponentUpdate(ctx context.Context, d *schema.ResourceData, m
 	// Warning or errors can be collected in a slice type
 	// var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 	id := d.Id()
 
 	// create new request for update
@@ -155,38 +210,40 @@ func resourceArdoqComponentUpdate(ctx context.Context, d *schema.ResourceData, m
 		req.Name = d.Get("name").(string)
 	}
 
-	if d.HasChange("parent") {
-		req.Parent = d.Get("parent").(string)
-	}
-
 	if d.HasChange("type_id") {
 		req.TypeID = d.Get("type_id").(string)
 	}
 
-	// check if custom fields are specified by checking len of the schema field "fields"
-	// if so, loop map and add each field to the request
-	if len(d.Get("fields").(map[string]interface{})) > 0 {
-		//create temporary map
-		fields := make(map[string]interface{})
+	// custom fields are either set as map of strings in "fields" or as JSON in "fields_json",
+	// removed custom fields are sent as null
+	fields, err := expandChangedCustomFields(ctx, c, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req.Fields = fields
 
-		// foreach key and value append to temporary fields maps
-		for k, v := range d.Get("fields").(map[string]interface{}) {
-			fields[k] = v.(string)
+	if d.HasChange("parent") {
+		if v := d.Get("parent").(string); v != "" {
+			req.Parent = v
+		} else {
+			// a nil Parent is left out of the request, the fields are merged into
+			// the request as is, so a null parent moves the component to the top level
+			req.Fields["parent"] = nil
 		}
-		// assign temporary fields map to req
-		req.Fields = fields
 	}
 
-	_, err := c.Components().Update(ctx, id, req)
+	expectVersion(c, d, req.Fields)
+
+	_, err = c.Components().Update(ctx, id, req)
 	if err != nil {
-		return diag.FromErr(err)
+		return updateError(c, "component", err)
 	}
 
 	return resourceArdoqComponentRead(ctx, d, m)
 }
 
 func resourceArdoqComponentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 	id := d.Id()
 
 	err := c.Components().Delete(ctx, id)
@@ -194,6 +251,124 @@ func resourceArdoqComponentDelete(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(err)
 	}
 
+	// Ardoq deletes the references of a deleted component, which is easy to miss when the
+	// component is replaced, i.e. to move it to another workspace. The counts are taken from
+	// the state, during a replace the new values are unknown
+	incoming, _ := d.GetChange("incoming_reference_count")
+	outgoing, _ := d.GetChange("outgoing_reference_count")
+
+	var diags diag.Diagnostics
+	if count := incoming.(int) + outgoing.(int); count > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Ardoq deleted the references from and to component %s", id),
+			Detail: fmt.Sprintf("Ardoq deletes the references of a deleted component, component %s had %d references. "+
+				"When the component is replaced, i.e. because root_workspace changed, only the references managed by Terraform "+
+				"are created again.", id, count),
+		})
+	}
+
 	d.SetId("")
-	return diag.Diagnostics{}
+	return diags
+}
+
+// resourceArdoqComponentResolveTypeName sets type_id to the id of the type named in type_name
+func resourceArdoqComponentResolveTypeName(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*apiClient)
+
+	// a changed type_id means the type will change, so its name will too
+	if d.HasChange("type_id") {
+		return d.SetNewComputed("type_name")
+	}
+
+	typeName := d.Get("type_name").(string)
+	if typeName == "" || !d.NewValueKnown("type_name") {
+		return nil
+	}
+
+	// only look up the type when it might change, this saves requests on every plan
+	if d.Id() != "" && !d.HasChanges("type_name", "root_workspace") {
+		return nil
+	}
+
+	if !d.NewValueKnown("root_workspace") {
+		return d.SetNewComputed("type_id")
+	}
+
+	model, err := workspaceModel(ctx, c, d.Get("root_workspace").(string))
+	if err != nil {
+		return err
+	}
+
+	typeID, err := componentTypeID(model, typeName)
+	if err != nil {
+		return err
+	}
+
+	return d.SetNew("type_id", typeID)
+}
+
+// resourceArdoqComponentValidateFields validates the custom fields against the fields
+// of the model of the workspace, so mistakes show up in the plan instead of during apply
+func resourceArdoqComponentValidateFields(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*apiClient)
+
+	// the workspace or fields can depend on resources that are not created yet, i.e. a field
+	// whose key is the name of an ardoq_field is unknown until the field is created
+	if !d.NewValueKnown("root_workspace") || !d.NewValueKnown("fields") || !d.NewValueKnown("fields_json") {
+		return nil
+	}
+
+	values, err := expandCustomFields(d)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 || !d.HasChanges("root_workspace", "type_id", "fields", "fields_json") {
+		return nil
+	}
+
+	workspace, err := c.Workspaces().Get(ctx, d.Get("root_workspace").(string))
+	if err != nil {
+		return fmt.Errorf("could not read workspace %s to validate fields: %w", d.Get("root_workspace").(string), err)
+	}
+
+	// when the type is not known yet, the fields of all types in the model are valid
+	typeID := ""
+	if d.NewValueKnown("type_id") {
+		typeID = d.Get("type_id").(string)
+	}
+
+	fields, err := modelFields(ctx, c, workspace.ComponentModel)
+	if err != nil {
+		return fmt.Errorf("could not read fields to validate fields: %w", err)
+	}
+
+	return validateFields(values, fields, typeID)
+}
+
+// resourceArdoqComponentValidateMove checks a new parent is in the workspace of the component. A component
+// can't be moved to another workspace, so the plan replaces it, see resourceArdoqComponentDelete
+func resourceArdoqComponentValidateMove(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*apiClient)
+
+	// the parent or workspace can depend on resources that are not created yet
+	if !d.HasChanges("parent", "root_workspace") || !d.NewValueKnown("parent") || !d.NewValueKnown("root_workspace") {
+		return nil
+	}
+
+	parent := d.Get("parent").(string)
+	if parent == "" {
+		return nil
+	}
+
+	component, err := c.Components().Read(ctx, parent)
+	if err != nil {
+		return fmt.Errorf("could not read parent %s: %w", parent, err)
+	}
+
+	if workspace := d.Get("root_workspace").(string); component.RootWorkspace != workspace {
+		return fmt.Errorf("parent %s is in workspace %s, it has to be in workspace %s of the component", parent, component.RootWorkspace, workspace)
+	}
+
+	return nil
 }
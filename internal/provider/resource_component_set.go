@@ -0,0 +1,566 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+func resourceArdoqComponentSet() *schema.Resource {
+	return &schema.Resource{
+		Description: "`ardoq_component_set` resource lets you manage many components of a workspace at once. " +
+			"The components are created, updated and deleted with the batch API of Ardoq, which is a lot faster than an " +
+			"`ardoq_component` for every component, and keeps the state small. A component is identified by its name, " +
+			"so names have to be unique in a set",
+		CreateContext: resourceArdoqComponentSetCreate,
+		ReadContext:   resourceArdoqComponentSetRead,
+		UpdateContext: resourceArdoqComponentSetUpdate,
+		DeleteContext: resourceArdoqComponentSetDelete,
+		CustomizeDiff: resourceArdoqComponentSetCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The unique ID of the component set",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"root_workspace": {
+				Description: "Id of the workspace the components belong to. Changing this forces a new component set",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"component": {
+				Description: "The components of the set",
+				Type:        schema.TypeSet,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description:  "Name of the component, unique in the set",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+						"description": {
+							Description: "Text field describing the component",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"type_id": {
+							Description: "Id of the component's type, conflicts with `type_name`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"type_name": {
+							Description: "Name of the component's type, the id of the type is looked up in the model of the workspace. Conflicts with `type_id`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"parent": {
+							Description: "Id of the component's parent, for a parent which is not in the set. Conflicts with `parent_name`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"parent_name": {
+							Description: "Name of the component's parent in the set. Conflicts with `parent`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"fields": {
+							Description: "All custom fields from the model end up here",
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"batch_size": {
+				Description:  "Maximum number of components created, updated or deleted with one request",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(1, 1000),
+			},
+			"ids": {
+				Description: "The ids of the components, by name",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// componentSetElement is a component of the set, as configured
+type componentSetElement struct {
+	name        string
+	description string
+	typeID      string
+	typeName    string
+	parent      string
+	parentName  string
+	fields      map[string]interface{}
+}
+
+func resourceArdoqComponentSetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// the id is set first, so the components created before a failure end up in the state
+	d.SetId(id.UniqueId())
+
+	diags := applyComponentSet(ctx, d, m)
+	if !diags.HasError() {
+		return resourceArdoqComponentSetRead(ctx, d, m)
+	}
+
+	if len(d.Get("ids").(map[string]interface{})) == 0 {
+		d.SetId("")
+		return diags
+	}
+
+	// Terraform taints a resource when its create fails, so the next apply would delete the components that
+	// were created and create them again. Instead the set is created with the components that were created,
+	// and the next plan shows the components that are missing
+	for i := range diags {
+		diags[i].Severity = diag.Warning
+		diags[i].Summary = "Not all components of the set were created, apply again to create the others: " + diags[i].Summary
+	}
+
+	return append(diags, resourceArdoqComponentSetRead(ctx, d, m)...)
+}
+
+func resourceArdoqComponentSetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c := m.(*apiClient)
+
+	components, err := c.Components().Search(ctx, &ardoq.ComponentSearchQuery{Workspace: d.Get("root_workspace").(string)})
+	if err != nil {
+		return handleNotFoundError(err, d, d.Id())
+	}
+
+	byID := make(map[string]*ardoq.Component, len(*components))
+	for i := range *components {
+		byID[(*components)[i].ID] = &(*components)[i]
+	}
+
+	ids := expandStringMap(d.Get("ids").(map[string]interface{}))
+	names := make(map[string]string, len(ids))
+	for name, id := range ids {
+		if _, ok := byID[id]; !ok {
+			log.Printf("[WARN] Removing component %s (%s) from %s because it's gone", name, id, d.Id())
+			delete(ids, name)
+			continue
+		}
+		names[id] = name
+	}
+
+	// the type and parent are set the same way as in the configuration, by id or by name
+	prior := expandComponentSetElements(d.Get("component").(*schema.Set))
+
+	var elements []interface{}
+	for name, id := range ids {
+		component := byID[id]
+
+		// the unknown attributes of a component are more than its custom fields, and fields
+		// set outside Terraform are not managed by the set, so only the fields in the state are kept
+		fields := make(map[string]interface{}, len(prior[name].fields))
+		for k := range prior[name].fields {
+			if v, ok := component.Fields[k]; ok {
+				fields[k] = v
+			}
+		}
+
+		element := map[string]interface{}{
+			"name":        name,
+			"description": component.Description,
+			"type_id":     "",
+			"type_name":   "",
+			"parent":      "",
+			"parent_name": "",
+			"fields":      convertFields(fields),
+		}
+
+		if prior[name].typeName != "" {
+			element["type_name"] = component.Type
+		} else if prior[name].typeID != "" {
+			element["type_id"] = component.TypeID
+		}
+
+		if parent, _ := component.Parent.(string); parent != "" {
+			if parentName, ok := names[parent]; ok && prior[name].parent == "" {
+				element["parent_name"] = parentName
+			} else {
+				element["parent"] = parent
+			}
+		}
+
+		elements = append(elements, element)
+	}
+
+	if err := d.Set("component", elements); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceArdoqComponentSetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if diags := applyComponentSet(ctx, d, m); diags.HasError() {
+		return diags
+	}
+
+	return resourceArdoqComponentSetRead(ctx, d, m)
+}
+
+func resourceArdoqComponentSetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+
+	ids := expandStringMap(d.Get("ids").(map[string]interface{}))
+	elements := expandComponentSetElements(d.Get("component").(*schema.Set))
+
+	if err := deleteComponentSetElements(ctx, c, d, ids, elements, sortedKeys(ids)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return diag.Diagnostics{}
+}
+
+// applyComponentSet creates, updates and deletes the components, so they match the configuration.
+// Components are created parents first, after the creates the updates are applied, so components can
+// move to a new parent, and last the components that were removed are deleted, children first
+func applyComponentSet(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+
+	oldComponents, newComponents := d.GetChange("component")
+	oldElements := expandComponentSetElements(oldComponents.(*schema.Set))
+	newElements := expandComponentSetElements(newComponents.(*schema.Set))
+
+	// the ids are unknown in the plan when components are added, so the ids of the state are used
+	oldIDs, _ := d.GetChange("ids")
+	ids := expandStringMap(oldIDs.(map[string]interface{}))
+
+	err := applyComponentSetElements(ctx, c, d, ids, oldElements, newElements)
+	if err != nil {
+		// keep the components that were created by keeping their ids, and the configuration of the last successful
+		// apply, so the next plan shows the changes that are not applied yet. A failed update keeps the state,
+		// Create turns the error into a warning, otherwise Terraform replaces the set
+		if setErr := d.Set("component", oldComponents); setErr != nil {
+			return diag.FromErr(setErr)
+		}
+	}
+
+	if setErr := d.Set("ids", ids); setErr != nil {
+		return diag.FromErr(setErr)
+	}
+
+	return diag.FromErr(err)
+}
+
+func applyComponentSetElements(ctx context.Context, c *apiClient, d *schema.ResourceData, ids map[string]string, oldElements, newElements map[string]componentSetElement) error {
+	rootWorkspace := d.Get("root_workspace").(string)
+	batchSize := d.Get("batch_size").(int)
+
+	typeIDs, err := componentSetTypeIDs(ctx, c, rootWorkspace, newElements)
+	if err != nil {
+		return err
+	}
+
+	var creates, updates, deletes []string
+	for name, element := range newElements {
+		if _, ok := ids[name]; !ok {
+			creates = append(creates, name)
+		} else if old, ok := oldElements[name]; !ok || !old.equal(element) {
+			// components created by a failed apply are not in the old elements, they are always updated
+			updates = append(updates, name)
+		}
+	}
+	for name := range ids {
+		if _, ok := newElements[name]; !ok {
+			deletes = append(deletes, name)
+		}
+	}
+
+	// a parent in the set has to be created before its children, so the components are created in waves by depth
+	for _, wave := range componentSetWaves(creates, newElements, false) {
+		for _, chunk := range chunkStrings(wave, batchSize) {
+			req := batchRequest{}
+			for _, name := range chunk {
+				body := componentSetBody(newElements[name], ids, typeIDs)
+				body["rootWorkspace"] = rootWorkspace
+				req.Components.Create = append(req.Components.Create, batchCreate{BatchID: name, Body: body})
+			}
+
+			res, err := c.Batch().Apply(ctx, req)
+			if err != nil {
+				return fmt.Errorf("could not create components %q: %w", chunk, err)
+			}
+			for _, created := range res.Components.Created {
+				ids[created.BatchID] = created.ID
+			}
+		}
+	}
+
+	sort.Strings(updates)
+	for _, chunk := range chunkStrings(updates, batchSize) {
+		req := batchRequest{}
+		for _, name := range chunk {
+			body := componentSetBody(newElements[name], ids, typeIDs)
+			// removed fields are sent as null, otherwise Ardoq keeps their old value
+			body["customFields"] = nullRemovedFields(body["customFields"].(map[string]interface{}), oldElements[name].fields)
+			req.Components.Update = append(req.Components.Update, batchUpdate{ID: ids[name], Body: body})
+		}
+
+		if _, err := c.Batch().Apply(ctx, req); err != nil {
+			return fmt.Errorf("could not update components %q: %w", chunk, err)
+		}
+	}
+
+	return deleteComponentSetElements(ctx, c, d, ids, oldElements, deletes)
+}
+
+// deleteComponentSetElements deletes the components by name, children first,
+// Ardoq deletes the children of a component together with the component
+func deleteComponentSetElements(ctx context.Context, c *apiClient, d *schema.ResourceData, ids map[string]string, elements map[string]componentSetElement, names []string) error {
+	for _, wave := range componentSetWaves(names, elements, true) {
+		for _, chunk := range chunkStrings(wave, d.Get("batch_size").(int)) {
+			req := batchRequest{}
+			for _, name := range chunk {
+				req.Components.Delete = append(req.Components.Delete, batchDelete{ID: ids[name]})
+			}
+
+			if _, err := c.Batch().Apply(ctx, req); err != nil {
+				return fmt.Errorf("could not delete components %q: %w", chunk, err)
+			}
+			for _, name := range chunk {
+				delete(ids, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// componentSetBody returns the body to create or update a component
+func componentSetBody(element componentSetElement, ids map[string]string, typeIDs map[string]string) map[string]interface{} {
+	body := map[string]interface{}{
+		"name":         element.name,
+		"description":  element.description,
+		"customFields": element.fields,
+		"parent":       nil,
+	}
+
+	if element.parentName != "" {
+		body["parent"] = ids[element.parentName]
+	} else if element.parent != "" {
+		body["parent"] = element.parent
+	}
+
+	if element.typeName != "" {
+		body["typeId"] = typeIDs[element.typeName]
+	} else if element.typeID != "" {
+		body["typeId"] = element.typeID
+	}
+
+	return body
+}
+
+// componentSetTypeIDs returns the ids of the component types used by name,
+// the model of the workspace is only read when type names are used
+func componentSetTypeIDs(ctx context.Context, c *apiClient, rootWorkspace string, elements map[string]componentSetElement) (map[string]string, error) {
+	typeIDs := make(map[string]string)
+
+	var model *ardoq.Model
+	for _, element := range elements {
+		if element.typeName == "" {
+			continue
+		}
+
+		if model == nil {
+			var err error
+			if model, err = workspaceModel(ctx, c, rootWorkspace); err != nil {
+				return nil, err
+			}
+		}
+
+		typeID, err := componentTypeID(model, element.typeName)
+		if err != nil {
+			return nil, fmt.Errorf("component %q: %w", element.name, err)
+		}
+		typeIDs[element.typeName] = typeID
+	}
+
+	return typeIDs, nil
+}
+
+// componentSetWaves groups the components by their depth in the set, parents first, or children first if reverse is set
+func componentSetWaves(names []string, elements map[string]componentSetElement, reverse bool) [][]string {
+	var waves [][]string
+
+	for _, name := range names {
+		depth := 0
+		for parent := elements[name].parentName; parent != ""; parent = elements[parent].parentName {
+			depth++
+			// a cycle is rejected during plan, but the state could still contain one
+			if depth > len(elements) {
+				break
+			}
+		}
+
+		for len(waves) <= depth {
+			waves = append(waves, nil)
+		}
+		waves[depth] = append(waves[depth], name)
+	}
+
+	for _, wave := range waves {
+		sort.Strings(wave)
+	}
+
+	if reverse {
+		for i, j := 0, len(waves)-1; i < j; i, j = i+1, j-1 {
+			waves[i], waves[j] = waves[j], waves[i]
+		}
+	}
+
+	return waves
+}
+
+func chunkStrings(list []string, size int) [][]string {
+	var chunks [][]string
+
+	for len(list) > size {
+		chunks = append(chunks, list[:size])
+		list = list[size:]
+	}
+	if len(list) > 0 {
+		chunks = append(chunks, list)
+	}
+
+	return chunks
+}
+
+// resourceArdoqComponentSetCustomizeDiff checks the names are unique and the parents exist,
+// the ids of new components are only known after the apply
+func resourceArdoqComponentSetCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("component") {
+		return d.SetNewComputed("ids")
+	}
+
+	components := d.Get("component").(*schema.Set).List()
+	elements := make(map[string]componentSetElement, len(components))
+	for _, component := range components {
+		element := expandComponentSetElement(component.(map[string]interface{}))
+		if element.name == "" {
+			continue
+		}
+		if _, ok := elements[element.name]; ok {
+			return fmt.Errorf("component %q: the name of a component has to be unique in the set", element.name)
+		}
+		elements[element.name] = element
+	}
+
+	for name, element := range elements {
+		if element.typeID != "" && element.typeName != "" {
+			return fmt.Errorf("component %q: only one of type_id and type_name can be set", name)
+		}
+		if element.parent != "" && element.parentName != "" {
+			return fmt.Errorf("component %q: only one of parent and parent_name can be set", name)
+		}
+		if element.parentName == "" {
+			continue
+		}
+		if _, ok := elements[element.parentName]; !ok {
+			return fmt.Errorf("component %q: parent_name %q is not a component in the set", name, element.parentName)
+		}
+		for parent, depth := element.parentName, 0; parent != ""; parent, depth = elements[parent].parentName, depth+1 {
+			if parent == name || depth > len(elements) {
+				return fmt.Errorf("component %q: parent_name %q makes the component its own ancestor", name, element.parentName)
+			}
+		}
+	}
+
+	ids := expandStringMap(d.Get("ids").(map[string]interface{}))
+	for name := range elements {
+		if _, ok := ids[name]; !ok {
+			return d.SetNewComputed("ids")
+		}
+	}
+	if len(ids) != len(elements) {
+		return d.SetNewComputed("ids")
+	}
+
+	return nil
+}
+
+func expandComponentSetElements(set *schema.Set) map[string]componentSetElement {
+	elements := make(map[string]componentSetElement, set.Len())
+	for _, component := range set.List() {
+		element := expandComponentSetElement(component.(map[string]interface{}))
+		// during apply the SDK returns an empty element for a changed element which had fields, names are never empty
+		if element.name == "" {
+			continue
+		}
+		elements[element.name] = element
+	}
+
+	return elements
+}
+
+func expandComponentSetElement(component map[string]interface{}) componentSetElement {
+	fields := make(map[string]interface{})
+	for k, v := range component["fields"].(map[string]interface{}) {
+		fields[k] = v.(string)
+	}
+
+	return componentSetElement{
+		name:        component["name"].(string),
+		description: component["description"].(string),
+		typeID:      component["type_id"].(string),
+		typeName:    component["type_name"].(string),
+		parent:      component["parent"].(string),
+		parentName:  component["parent_name"].(string),
+		fields:      fields,
+	}
+}
+
+func (e componentSetElement) equal(other componentSetElement) bool {
+	if e.name != other.name || e.description != other.description || e.typeID != other.typeID ||
+		e.typeName != other.typeName || e.parent != other.parent || e.parentName != other.parentName ||
+		len(e.fields) != len(other.fields) {
+		return false
+	}
+
+	for k, v := range e.fields {
+		if w, ok := other.fields[k]; !ok || v != w {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expandStringMap converts a map from the schema to a map[string]string
+func expandStringMap(m map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v.(string)
+	}
+
+	return result
+}
@@ -0,0 +1,300 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceComponentSet_lifecycle(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponentSet()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"batch_size":     2,
+		"component": []interface{}{
+			map[string]interface{}{"name": "child", "parent_name": "parent", "type_name": "Application", "fields": map[string]interface{}{"owner": "me"}},
+			map[string]interface{}{"name": "grandchild", "parent_name": "child"},
+			map[string]interface{}{"name": "parent", "description": "TestAcc"},
+			map[string]interface{}{"name": "other", "description": "TestAcc"},
+		},
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"ids.%": "4",
+	})
+
+	// parents are created before their children, one batch for every level of the set
+	if got := f.requestCount(http.MethodPost, "v2/batch"); got != 3 {
+		t.Errorf("created the components with %d batches, want 3", got)
+	}
+
+	parent := f.get("component", state.Attributes["ids.parent"])
+	child := f.get("component", state.Attributes["ids.child"])
+	grandchild := f.get("component", state.Attributes["ids.grandchild"])
+	if parent == nil || child == nil || grandchild == nil {
+		t.Fatalf("components were not created: %v", state.Attributes)
+	}
+	if child["parent"] != parent["_id"] || grandchild["parent"] != child["_id"] {
+		t.Errorf("child parent = %v, grandchild parent = %v, want %v and %v", child["parent"], grandchild["parent"], parent["_id"], child["_id"])
+	}
+	if child["typeId"] != "p1" || child["owner"] != "me" || child["rootWorkspace"] != workspace {
+		t.Errorf("child = %v, want type p1, owner me and root workspace %s", child, workspace)
+	}
+
+	// a refresh without changes plans nothing
+	state = testRefreshResource(t, r, state, meta)
+	requests := f.requestCount(http.MethodPost, "v2/batch")
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"batch_size":     2,
+		"component": []interface{}{
+			map[string]interface{}{"name": "child", "parent_name": "parent", "type_name": "Application", "fields": map[string]interface{}{"owner": "me"}},
+			map[string]interface{}{"name": "grandchild", "parent_name": "child"},
+			map[string]interface{}{"name": "parent", "description": "TestAcc"},
+			map[string]interface{}{"name": "other", "description": "TestAcc"},
+		},
+	}, meta)
+	if got := f.requestCount(http.MethodPost, "v2/batch"); got != requests {
+		t.Errorf("applying the same configuration sent %d batches, want none", got-requests)
+	}
+
+	// the child moves to the top level and loses its field, other is deleted
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"batch_size":     2,
+		"component": []interface{}{
+			map[string]interface{}{"name": "child", "type_name": "Application"},
+			map[string]interface{}{"name": "grandchild", "parent_name": "child"},
+			map[string]interface{}{"name": "parent", "description": "TestAcc updated"},
+			map[string]interface{}{"name": "new", "parent_name": "parent"},
+		},
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"ids.%":     "4",
+		"ids.other": "",
+	})
+
+	child = f.get("component", state.Attributes["ids.child"])
+	if _, ok := child["parent"]; ok {
+		t.Errorf("child parent = %v, want none", child["parent"])
+	}
+	if _, ok := child["owner"]; ok {
+		t.Errorf("child owner = %v, want none", child["owner"])
+	}
+	if parent := f.get("component", state.Attributes["ids.parent"]); parent["description"] != "TestAcc updated" {
+		t.Errorf("parent description = %v, want %q", parent["description"], "TestAcc updated")
+	}
+	if len(f.ids("component")) != 4 {
+		t.Errorf("got %d components, want 4", len(f.ids("component")))
+	}
+
+	testDestroyResource(t, r, state, meta)
+	if ids := f.ids("component"); len(ids) != 0 {
+		t.Errorf("components %q were not deleted", ids)
+	}
+}
+
+func TestResourceComponentSet_fieldsOutsideTerraform(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponentSet()
+
+	config := map[string]interface{}{
+		"root_workspace": workspace,
+		"component": []interface{}{
+			map[string]interface{}{"name": "one", "type_name": "Application", "fields": map[string]interface{}{"owner": "me"}},
+		},
+	}
+
+	state := testApplyResource(t, r, nil, config, meta)
+
+	// a field which is not in the configuration is set in the Ardoq app
+	component := f.get("component", state.Attributes["ids.one"])
+	component["cost"] = "100"
+	f.put("component", component)
+
+	state = testRefreshResource(t, r, state, meta)
+	for k, v := range state.Attributes {
+		if strings.HasSuffix(k, ".fields.cost") {
+			t.Errorf("%s = %s, want fields outside the configuration to be left out", k, v)
+		}
+	}
+
+	requests := f.requestCount(http.MethodPost, "v2/batch")
+	testApplyResource(t, r, state, config, meta)
+	if got := f.requestCount(http.MethodPost, "v2/batch"); got != requests {
+		t.Errorf("applying the same configuration sent %d batches, want none", got-requests)
+	}
+	if cost := f.get("component", state.Attributes["ids.one"])["cost"]; cost != "100" {
+		t.Errorf("cost = %v, want the field set outside Terraform to be kept", cost)
+	}
+}
+
+func TestResourceComponentSet_deletedOutsideTerraform(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponentSet()
+
+	config := map[string]interface{}{
+		"root_workspace": workspace,
+		"component": []interface{}{
+			map[string]interface{}{"name": "one"},
+			map[string]interface{}{"name": "two"},
+		},
+	}
+
+	state := testApplyResource(t, r, nil, config, meta)
+	f.delete("component", state.Attributes["ids.two"])
+
+	state = testRefreshResource(t, r, state, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"ids.%":   "1",
+		"ids.two": "",
+	})
+
+	// the component is created again
+	state = testApplyResource(t, r, state, config, meta)
+	if f.get("component", state.Attributes["ids.two"]) == nil {
+		t.Errorf("component two was not created again: %v", state.Attributes)
+	}
+}
+
+func TestResourceComponentSet_failedBatch(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMetaWithConfig(t, f, map[string]interface{}{"max_retries": 0})
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponentSet()
+
+	config := map[string]interface{}{
+		"root_workspace": workspace,
+		"component": []interface{}{
+			map[string]interface{}{"name": "parent"},
+			map[string]interface{}{"name": "child", "parent_name": "parent"},
+		},
+	}
+
+	// nothing is created when the first batch fails
+	f.fail(http.MethodPost, "v2/batch", http.StatusInternalServerError, 1)
+	if state, err := testApplyResourceErr(r, nil, config, meta); err == nil || state != nil {
+		t.Fatalf("got error %v and state %v, want the create to fail without a set", err, state)
+	}
+
+	// the parent is created, the batch with the child fails. Terraform would taint a set whose create
+	// fails and replace it, which deletes the parent, so the set is created with a warning instead
+	f.failAfter(http.MethodPost, "v2/batch", 1, http.StatusInternalServerError, 1)
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, diags := r.Apply(context.Background(), nil, diff, meta)
+	if diags.HasError() || len(diags) == 0 || !strings.Contains(diags[0].Summary, "Not all components of the set were created") ||
+		!strings.Contains(diags[0].Summary, "could not create components") {
+		t.Fatalf("diagnostics = %v, want a warning that the child was not created", diags)
+	}
+	first := state.Attributes["ids.parent"]
+	if state.ID == "" || first == "" || state.Attributes["ids.child"] != "" {
+		t.Fatalf("state = %v, want a set with only the parent", state)
+	}
+
+	// the next plan creates the child and keeps the parent
+	state = testApplyResource(t, r, state, config, meta)
+	if state.Attributes["ids.parent"] != first || state.Attributes["ids.child"] == "" {
+		t.Fatalf("ids = %v, want the parent %s and the child", state.Attributes, first)
+	}
+
+	// the update of the parent fails after the new component was created
+	updated := map[string]interface{}{
+		"root_workspace": workspace,
+		"component": []interface{}{
+			map[string]interface{}{"name": "parent", "description": "updated"},
+			map[string]interface{}{"name": "child", "parent_name": "parent"},
+			map[string]interface{}{"name": "new"},
+		},
+	}
+	f.failAfter(http.MethodPost, "v2/batch", 1, http.StatusInternalServerError, 1)
+	state, err = testApplyResourceErr(r, state, updated, meta)
+	if err == nil || !strings.Contains(err.Error(), "could not update components") {
+		t.Fatalf("got error %v, want the update to fail", err)
+	}
+	if state.Attributes["ids.parent"] != first || state.Attributes["ids.new"] == "" {
+		t.Errorf("ids = %v, want the parent %s and the new component", state.Attributes, first)
+	}
+
+	// Terraform keeps the state of a failed update, so the next apply updates the components instead of creating them again
+	state = testApplyResource(t, r, state, updated, meta)
+	if got := len(f.ids("component")); got != 3 {
+		t.Errorf("got %d components, want 3", got)
+	}
+	if parent := f.get("component", first); parent["description"] != "updated" {
+		t.Errorf("parent description = %v, want %q", parent["description"], "updated")
+	}
+	if state.Attributes["ids.parent"] != first {
+		t.Errorf("ids.parent = %q, want %q", state.Attributes["ids.parent"], first)
+	}
+}
+
+func TestResourceComponentSet_invalid(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponentSet()
+
+	for name, tc := range map[string]struct {
+		components []interface{}
+		want       string
+	}{
+		"duplicate name": {
+			components: []interface{}{
+				map[string]interface{}{"name": "one"},
+				map[string]interface{}{"name": "one", "description": "other"},
+			},
+			want: "has to be unique",
+		},
+		"unknown parent": {
+			components: []interface{}{
+				map[string]interface{}{"name": "one", "parent_name": "two"},
+			},
+			want: "is not a component in the set",
+		},
+		"cycle": {
+			components: []interface{}{
+				map[string]interface{}{"name": "one", "parent_name": "two"},
+				map[string]interface{}{"name": "two", "parent_name": "one"},
+			},
+			want: "its own ancestor",
+		},
+		"both types": {
+			components: []interface{}{
+				map[string]interface{}{"name": "one", "type_id": "p1", "type_name": "Application"},
+			},
+			want: "only one of type_id and type_name",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := testApplyResourceErr(r, nil, map[string]interface{}{
+				"root_workspace": workspace,
+				"component":      tc.components,
+			}, meta)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, want %q", err, tc.want)
+			}
+		})
+	}
+
+	if got := f.requestCount(http.MethodPost, "v2/batch"); got != 0 {
+		t.Errorf("sent %d batches for invalid configurations, want none", got)
+	}
+}
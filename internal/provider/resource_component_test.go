@@ -1,12 +1,16 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccResourceComponent_basic(t *testing.T) {
@@ -117,3 +121,537 @@ func testAccResourceComponent_fullUpdate(RootWorkspace, componentName string) st
 	}
 `, RootWorkspace, componentName)
 }
+
+func TestResourceComponent_clearAttributes(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponent()
+
+	parent := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "parent",
+	}, meta)
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "child",
+		"description":    "some description",
+		"parent":         parent.ID,
+		"fields": map[string]interface{}{
+			"owner": "me",
+			"cost":  "10",
+		},
+	}, meta)
+
+	if got := f.get("component", state.ID)["parent"]; got != parent.ID {
+		t.Fatalf("parent = %v, want %s", got, parent.ID)
+	}
+
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "child",
+		"fields": map[string]interface{}{
+			"owner": "me",
+		},
+	}, meta)
+
+	body := f.lastRequest(t, "PATCH", "component/"+state.ID).Body
+	for _, key := range []string{"parent", "cost"} {
+		if v, ok := body[key]; !ok || v != nil {
+			t.Errorf("update request %s = %v, want null", key, v)
+		}
+	}
+	if v, ok := body["description"]; !ok || v != "" {
+		t.Errorf("update request description = %v, want empty", v)
+	}
+
+	component := f.get("component", state.ID)
+	for _, key := range []string{"parent", "cost"} {
+		if v, ok := component[key]; ok {
+			t.Errorf("component %s = %v, want it cleared", key, v)
+		}
+	}
+
+	testCheckAttributes(t, state, map[string]string{
+		"parent":       "",
+		"description":  "",
+		"fields.%":     "1",
+		"fields.owner": "me",
+	})
+}
+
+func TestResourceComponent_keepBuiltInAttributes(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponent()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"fields":         map[string]interface{}{"owner": "me", "cost": "10"},
+	}, meta)
+
+	// the icon is set in the Ardoq app, it ends up in the fields of the state because it's an unknown attribute
+	component := f.get("component", state.ID)
+	component["icon"] = "fa-cloud"
+	f.put("component", component)
+	state = testRefreshResource(t, r, state, meta)
+	testCheckAttributes(t, state, map[string]string{"fields.icon": "fa-cloud"})
+
+	testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"fields":         map[string]interface{}{"owner": "me"},
+	}, meta)
+
+	body := f.lastRequest(t, "PATCH", "component/"+state.ID).Body
+	if v, ok := body["cost"]; !ok || v != nil {
+		t.Errorf("update request cost = %v, want null", v)
+	}
+	if v, ok := body["icon"]; ok {
+		t.Errorf("update request icon = %v, want the built-in attribute left out", v)
+	}
+	if icon := f.get("component", state.ID)["icon"]; icon != "fa-cloud" {
+		t.Errorf("icon = %v, want it kept", icon)
+	}
+}
+
+func TestResourceComponent_lifecycle(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponent()
+
+	parent := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "parent",
+		"description":    "TestAcc",
+	}, meta)
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"description":    "TestAcc",
+		"type_name":      "Application",
+		"parent":         parent.ID,
+		"fields": map[string]interface{}{
+			"owner": "me",
+		},
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"name":         "component",
+		"parent":       parent.ID,
+		"type_id":      "p1",
+		"type_name":    "Application",
+		"fields.owner": "me",
+	})
+	testImportResourceVerify(t, r, state, meta)
+
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"description":    "TestAcc updated",
+		"type_name":      "Application",
+		"fields_json":    `{"cost":10,"owner":"you"}`,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"description": "TestAcc updated",
+		"parent":      "",
+		"fields.%":    "0",
+		"fields_json": `{"cost":10,"owner":"you"}`,
+	})
+	// an import can't know fields_json was used, so the fields end up in "fields"
+	testImportResourceVerify(t, r, state, meta, "fields", "fields_json")
+
+	testDestroyResource(t, r, state, meta)
+	if component := f.get("component", state.ID); component != nil {
+		t.Errorf("component %s was not deleted", state.ID)
+	}
+}
+
+func TestResourceComponent_deletedOutsideTerraform(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponent()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+	}, meta)
+
+	f.delete("component", state.ID)
+
+	if state := testRefreshResource(t, r, state, meta); state != nil {
+		t.Errorf("state = %v, want the component removed from the state", state)
+	}
+}
+
+func TestResourceComponent_validateFields(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	f.put("field", map[string]interface{}{"name": "latency", "type": fieldTypeNumber, "model": "model1", "globalref": true})
+
+	r := resourceArdoqComponent()
+
+	for name, tc := range map[string]struct {
+		fields map[string]interface{}
+		want   string
+	}{
+		"invalid value":  {fields: map[string]interface{}{"cost": "a lot"}, want: `fields["cost"]`},
+		"reference only": {fields: map[string]interface{}{"latency": "10"}, want: `fields["latency"]: the field doesn't apply to the component type`},
+		"misspelled":     {fields: map[string]interface{}{"ownr": "me"}, want: `fields["ownr"]: unknown field, valid fields are ["cost" "owner"]`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := testApplyResourceErr(r, nil, map[string]interface{}{
+				"root_workspace": workspace,
+				"name":           "component",
+				"fields":         tc.fields,
+			}, meta)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, want %q", err, tc.want)
+			}
+		})
+	}
+
+	// a field whose key is the name of an ardoq_field created in the same apply is unknown during plan
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"fields":         map[string]interface{}{"cost": "10", "runtime": testUnknownValue},
+	}), meta)
+	if err != nil {
+		t.Errorf("got error %v, want the fields to be validated once they are known", err)
+	}
+	if diff == nil {
+		t.Error("got no diff, want the component to be created")
+	}
+}
+
+func TestResourceComponent_metadata(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponent()
+
+	config := map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "parent",
+		"fields_json":    `{"owner":"me","cost":10}`,
+	}
+	state := testApplyResource(t, r, nil, config, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"version":                  "1",
+		"created":                  "2024-01-01T00:01:00Z",
+		"created_by":               "user1",
+		"created_by_name":          "Terraform",
+		"created_by_email":         "terraform@example.com",
+		"last_updated":             "2024-01-01T00:01:00Z",
+		"last_modified_by_name":    "Terraform",
+		"children_count":           "0",
+		"incoming_reference_count": "0",
+	})
+	if state.Attributes["component_key"] == "" || state.Attributes["ardoq_persistent.ordering"] == "" {
+		t.Errorf("state = %v, want the component key and the ordering", state.Attributes)
+	}
+
+	child := f.put("component", map[string]interface{}{"name": "child", "rootWorkspace": workspace, "parent": state.ID})
+	f.put("reference", map[string]interface{}{"source": child, "target": state.ID, "rootWorkspace": workspace, "targetWorkspace": workspace, "type": 2})
+
+	state = testRefreshResource(t, r, state, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"children_count":           "1",
+		"incoming_reference_count": "1",
+		"outgoing_reference_count": "0",
+	})
+
+	// the counts and equivalent fields_json don't change the component, so nothing is planned
+	config["fields_json"] = `{"cost": 10, "owner": "me"}`
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Errorf("diff = %v, want none", diff)
+	}
+
+	// an update changes the version and the time of the last update, which are unknown until it's applied
+	config["description"] = "TestAcc"
+	diff, err = r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range auditAttributesOnUpdate {
+		if attribute, ok := diff.Attributes[key]; !ok || !attribute.NewComputed {
+			t.Errorf("diff %s = %v, want it to be computed", key, attribute)
+		}
+	}
+	if _, ok := diff.Attributes["created"]; ok {
+		t.Errorf("diff created = %v, want no change", diff.Attributes["created"])
+	}
+
+	state = testApplyResource(t, r, state, config, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"version":      "2",
+		"created":      "2024-01-01T00:01:00Z",
+		"last_updated": "2024-01-01T00:02:00Z",
+	})
+}
+
+func TestResourceComponent_optimisticConcurrency(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponent()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+	}, meta)
+
+	// the update is made with the version from the state
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"description":    "TestAcc",
+	}, meta)
+	if version := f.lastRequest(t, "PATCH", "component/"+state.ID).Body["_version"]; version != float64(1) {
+		t.Errorf("update request _version = %v, want 1", version)
+	}
+
+	// the component is changed in the Ardoq app after the plan
+	component := f.get("component", state.ID)
+	component["description"] = "changed in the app"
+	component["_version"] = 3
+	f.put("component", component)
+
+	_, err := testApplyResourceErr(r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"description":    "TestAcc updated",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), "component modified outside Terraform since plan; re-run plan") {
+		t.Fatalf("got error %v, want a version conflict", err)
+	}
+	if description := f.get("component", state.ID)["description"]; description != "changed in the app" {
+		t.Errorf("description = %v, want the change from the app kept", description)
+	}
+
+	// after a refresh the plan has the new version
+	state = testRefreshResource(t, r, state, meta)
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"description":    "TestAcc updated",
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"description": "TestAcc updated",
+		"version":     "4",
+	})
+}
+
+func TestResourceComponent_optimisticConcurrencyDisabled(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMetaWithConfig(t, f, map[string]interface{}{"optimistic_concurrency": false})
+	workspace := testFakeWorkspace(f)
+
+	r := resourceArdoqComponent()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+	}, meta)
+
+	component := f.get("component", state.ID)
+	component["description"] = "changed in the app"
+	component["_version"] = 3
+	f.put("component", component)
+
+	// without the version the change from the app is overwritten
+	testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"description":    "TestAcc",
+	}, meta)
+	if _, ok := f.lastRequest(t, "PATCH", "component/"+state.ID).Body["_version"]; ok {
+		t.Errorf("update request has a _version, want none")
+	}
+	if description := f.get("component", state.ID)["description"]; description != "TestAcc" {
+		t.Errorf("description = %v, want %q", description, "TestAcc")
+	}
+}
+
+func TestResourceComponent_move(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	other := f.put("workspace", map[string]interface{}{"name": "Other", "componentModel": "model1"})
+	first := f.put("component", map[string]interface{}{"name": "first", "rootWorkspace": workspace})
+	second := f.put("component", map[string]interface{}{"name": "second", "rootWorkspace": workspace})
+	remote := f.put("component", map[string]interface{}{"name": "remote", "rootWorkspace": other})
+	target := f.put("component", map[string]interface{}{"name": "target", "rootWorkspace": workspace})
+
+	r := resourceArdoqComponent()
+	ref := resourceArdoqReference()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"parent":         first,
+	}, meta)
+	id := state.ID
+
+	reference := testApplyResource(t, ref, nil, map[string]interface{}{
+		"source": id,
+		"target": target,
+		"type":   2,
+	}, meta)
+
+	// a new parent moves the component, it keeps its id and references
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"parent":         second,
+	}, meta)
+	if state.ID != id {
+		t.Errorf("id = %s, want the component %s moved", state.ID, id)
+	}
+	if parent := f.get("component", id)["parent"]; parent != second {
+		t.Errorf("parent = %v, want %s", parent, second)
+	}
+	if f.get("reference", reference.ID) == nil {
+		t.Errorf("reference %s was deleted, want it kept", reference.ID)
+	}
+
+	// a parent in another workspace is rejected during plan
+	if _, err := testApplyResourceErr(r, state, map[string]interface{}{
+		"root_workspace": workspace,
+		"name":           "component",
+		"parent":         remote,
+	}, meta); err == nil || !strings.Contains(err.Error(), "parent "+remote+" is in workspace "+other) {
+		t.Errorf("got error %v, want the parent to be in the workspace of the component", err)
+	}
+	if count := f.requestCount("PATCH", "component/"+id); count != 1 {
+		t.Errorf("the component was updated %d times, want 1", count)
+	}
+
+	// another workspace replaces the component
+	config := map[string]interface{}{
+		"root_workspace": other,
+		"name":           "component",
+		"parent":         remote,
+	}
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.RequiresNew() {
+		t.Errorf("diff = %v, want the component to be replaced", diff)
+	}
+
+	// the references deleted with the old component are reported
+	state, diags := r.Apply(context.Background(), state, diff, meta)
+	if diags.HasError() {
+		t.Fatalf("apply failed: %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Detail, "component "+id+" had 1 references") {
+		t.Errorf("diagnostics = %v, want a warning about the deleted reference", diags)
+	}
+	if state.ID == id || f.get("component", id) != nil {
+		t.Errorf("id = %s, want component %s replaced", state.ID, id)
+	}
+	if moved := f.get("component", state.ID); moved["rootWorkspace"] != other || moved["parent"] != remote {
+		t.Errorf("component = %v, want it in workspace %s under %s", moved, other, remote)
+	}
+
+	// the reference from the old component is replaced as well
+	diff, err = ref.Diff(context.Background(), reference, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"source": testUnknownValue,
+		"target": target,
+		"type":   2,
+	}), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.RequiresNew() {
+		t.Errorf("diff = %v, want the reference to be replaced", diff)
+	}
+}
+
+func TestResourceComponent_errors(t *testing.T) {
+	for _, code := range []int{404, 409, 429, 500} {
+		code := code
+		t.Run(fmt.Sprint(code), func(t *testing.T) {
+			f := newFakeArdoq(t)
+			// without retries every error is returned
+			meta := testFakeMetaWithConfig(t, f, map[string]interface{}{"max_retries": 0})
+			workspace := testFakeWorkspace(f)
+
+			r := resourceArdoqComponent()
+
+			f.fail("POST", "component", code, 1)
+			if _, err := testApplyResourceErr(r, nil, map[string]interface{}{
+				"root_workspace": workspace,
+				"name":           "component",
+			}, meta); err == nil || !strings.Contains(err.Error(), fmt.Sprint(code)) {
+				t.Fatalf("create error = %v, want status code %d", err, code)
+			}
+
+			state := testApplyResource(t, r, nil, map[string]interface{}{
+				"root_workspace": workspace,
+				"name":           "component",
+			}, meta)
+
+			f.fail("PATCH", "component/"+state.ID, code, 1)
+			if _, err := testApplyResourceErr(r, state, map[string]interface{}{
+				"root_workspace": workspace,
+				"name":           "renamed",
+			}, meta); err == nil || !strings.Contains(err.Error(), fmt.Sprint(code)) {
+				t.Fatalf("update error = %v, want status code %d", err, code)
+			}
+			if name := f.get("component", state.ID)["name"]; name != "component" {
+				t.Errorf("name = %v, want the component unchanged", name)
+			}
+
+			// a 404 while reading means the component is gone, any other error fails the refresh
+			f.fail("GET", "component/"+state.ID, code, 1)
+			refreshed, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+			if code == 404 {
+				if diags.HasError() || refreshed != nil {
+					t.Errorf("refresh = %v, %v, want the component removed from the state", refreshed, diags)
+				}
+			} else if !diags.HasError() {
+				t.Errorf("refresh succeeded, want status code %d", code)
+			}
+		})
+	}
+}
+
+// testFakeWorkspace adds a workspace to the fake API, its model has the component type
+// "Application" with id "p1", the reference type "Implicit" with id 2 and the fields
+// "owner" and "cost". It returns the id of the workspace
+func testFakeWorkspace(f *fakeArdoq) string {
+	f.put("model", map[string]interface{}{
+		"_id":  "model1",
+		"name": "Test",
+		"root": map[string]interface{}{
+			"p1": map[string]interface{}{"id": "p1", "name": "Application"},
+		},
+		"referenceTypes": map[string]interface{}{
+			"2": map[string]interface{}{"id": 2, "name": "Implicit"},
+		},
+	})
+	f.put("field", map[string]interface{}{"name": "owner", "type": fieldTypeText, "model": "model1", "global": true, "globalref": true})
+	f.put("field", map[string]interface{}{"name": "cost", "type": fieldTypeNumber, "model": "model1", "global": true, "globalref": true})
+
+	return f.put("workspace", map[string]interface{}{"name": "Test", "componentModel": "model1"})
+}
@@ -0,0 +1,279 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	ardoq "github.com/mories76/ardoq-client-go/pkg"
+)
+
+// field types as known by Ardoq
+const (
+	fieldTypeText               = "Text"
+	fieldTypeTextarea           = "Textarea"
+	fieldTypeNumber             = "Number"
+	fieldTypeURL                = "Url"
+	fieldTypeEmail              = "Email"
+	fieldTypeDateTime           = "DateTime"
+	fieldTypeList               = "List"
+	fieldTypeSelectMultipleList = "SelectMultipleList"
+	fieldTypeCheckbox           = "Checkbox"
+)
+
+var fieldTypes = []string{
+	fieldTypeText,
+	fieldTypeTextarea,
+	fieldTypeNumber,
+	fieldTypeURL,
+	fieldTypeEmail,
+	fieldTypeDateTime,
+	fieldTypeList,
+	fieldTypeSelectMultipleList,
+	fieldTypeCheckbox,
+}
+
+func resourceArdoqField() *schema.Resource {
+	return &schema.Resource{
+		Description:   "`ardoq_field` resource lets you create a custom field for the components and references of a model",
+		CreateContext: resourceArdoqFieldCreate,
+		ReadContext:   resourceArdoqFieldRead,
+		UpdateContext: resourceArdoqFieldUpdate,
+		DeleteContext: resourceArdoqFieldDelete,
+		CustomizeDiff: resourceArdoqFieldCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The unique ID of the field",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"name": {
+				Description: "Name of the field, this is the key used in the `fields` of components and references. Changing this forces a new field",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"label": {
+				Description: "Label of the field, as shown in Ardoq",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"type": {
+				Description:  fmt.Sprintf("Type of the field, one of `%v`. Changing this forces a new field", fieldTypes),
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(fieldTypes, false),
+			},
+			"model": {
+				Description: "Id of the model the field belongs to. Changing this forces a new field",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"component_type": {
+				Description: "Id's of the component types the field applies to",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"reference_type": {
+				Description: "Id's of the reference types the field applies to",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"global": {
+				Description: "The field applies to all component types of the model",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"global_ref": {
+				Description: "The field applies to all reference types of the model",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"description": {
+				Description: "Text field describing the field",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"default_value": {
+				Description: "Default value",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"options": {
+				Description: "The options to select from, only for fields of type `List` and `SelectMultipleList`",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"order": {
+				Description: "Order",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceArdoqFieldCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+
+	req := fieldRequest{
+		Name:          d.Get("name").(string),
+		Label:         d.Get("label").(string),
+		Type:          d.Get("type").(string),
+		Model:         d.Get("model").(string),
+		Global:        d.Get("global").(bool),
+		GlobalRef:     d.Get("global_ref").(bool),
+		ComponentType: expandStringList(d.Get("component_type").(*schema.Set).List()),
+		ReferenceType: expandStringList(d.Get("reference_type").(*schema.Set).List()),
+	}
+
+	if v, ok := d.GetOk("description"); ok {
+		req.Description = v.(string)
+	}
+
+	if v, ok := d.GetOk("default_value"); ok {
+		req.DefaultValue = v.(string)
+	}
+
+	if v, ok := d.GetOk("options"); ok {
+		req.Options = expandStringList(v.([]interface{}))
+	}
+
+	field, err := c.Fields().Create(ctx, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(field.ID)
+
+	return resourceArdoqFieldRead(ctx, d, m)
+}
+
+func resourceArdoqFieldRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c := m.(*apiClient)
+
+	field, err := c.Fields().Read(ctx, d.Id())
+	if err != nil {
+		return handleNotFoundError(err, d, d.Id())
+	}
+
+	for key, val := range flattenFieldResource(field) {
+		if err := d.Set(key, val); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
+
+func resourceArdoqFieldUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+	id := d.Id()
+
+	// create new request for update
+	req := fieldRequest{}
+
+	// update field if changes are detected
+	if d.HasChange("label") {
+		req.Label = d.Get("label").(string)
+	}
+
+	if d.HasChange("description") {
+		req.Description = d.Get("description").(string)
+	}
+
+	if d.HasChange("default_value") {
+		req.DefaultValue = d.Get("default_value").(string)
+	}
+
+	if d.HasChange("global") {
+		req.Global = d.Get("global").(bool)
+	}
+
+	if d.HasChange("global_ref") {
+		req.GlobalRef = d.Get("global_ref").(bool)
+	}
+
+	if d.HasChange("component_type") {
+		req.ComponentType = expandStringList(d.Get("component_type").(*schema.Set).List())
+	}
+
+	if d.HasChange("reference_type") {
+		req.ReferenceType = expandStringList(d.Get("reference_type").(*schema.Set).List())
+	}
+
+	if d.HasChange("options") {
+		req.Options = expandStringList(d.Get("options").([]interface{}))
+	}
+
+	_, err := c.Fields().Update(ctx, id, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceArdoqFieldRead(ctx, d, m)
+}
+
+func resourceArdoqFieldDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+
+	err := c.Fields().Delete(ctx, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return diag.Diagnostics{}
+}
+
+// resourceArdoqFieldCustomizeDiff makes sure options are only used for select lists
+func resourceArdoqFieldCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	fieldType := d.Get("type").(string)
+	if fieldType == fieldTypeList || fieldType == fieldTypeSelectMultipleList {
+		return nil
+	}
+
+	if len(d.Get("options").([]interface{})) > 0 {
+		return fmt.Errorf("options can only be set for fields of type %s or %s, not for %s", fieldTypeList, fieldTypeSelectMultipleList, fieldType)
+	}
+
+	return nil
+}
+
+func flattenFieldResource(field *ardoq.Field) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             field.ID,
+		"name":           field.Name,
+		"label":          field.Label,
+		"type":           field.Type,
+		"model":          field.Model,
+		"component_type": field.ComponentType,
+		"reference_type": field.ReferenceType,
+		"global":         field.Global,
+		"global_ref":     field.GlobalRef,
+		"description":    field.Description,
+		"default_value":  field.DefaultValue,
+		"options":        fieldOptions(field),
+		"order":          field.Order,
+	}
+}
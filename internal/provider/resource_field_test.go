@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceField_basic(t *testing.T) {
+	t.Parallel()
+
+	name := fmt.Sprintf("tf_test_%s", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceField_basic(name, "Status", `["Active", "Retired"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ardoq_field.my-field", "name", name),
+					resource.TestCheckResourceAttr("ardoq_field.my-field", "options.#", "2"),
+					resource.TestCheckResourceAttr("ardoq_component.my-component", "fields."+name, "Active"),
+				),
+			},
+			{
+				ResourceName:      "ardoq_field.my-field",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccResourceField_basic(name, "Lifecycle status", `["Active", "Phasing out", "Retired"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ardoq_field.my-field", "label", "Lifecycle status"),
+					resource.TestCheckResourceAttr("ardoq_field.my-field", "options.1", "Phasing out"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceField_basic(name, label, options string) string {
+	return fmt.Sprintf(`
+resource "ardoq_model" "my-model" {
+  name = "%[1]s"
+
+  component_type {
+    name = "Application"
+  }
+}
+
+resource "ardoq_workspace" "my-workspace" {
+  name            = "%[1]s"
+  component_model = ardoq_model.my-model.id
+}
+
+resource "ardoq_field" "my-field" {
+  name           = "%[1]s"
+  label          = "%[2]s"
+  type           = "List"
+  model          = ardoq_model.my-model.id
+  component_type = [ardoq_model.my-model.component_types["Application"]]
+  options        = %[3]s
+}
+
+resource "ardoq_component" "my-component" {
+  root_workspace = ardoq_workspace.my-workspace.id
+  name           = "%[1]s"
+  type_id        = ardoq_model.my-model.component_types["Application"]
+  fields = {
+    (ardoq_field.my-field.name) = "Active"
+  }
+}
+`, name, label, options)
+}
+
+func TestResourceField_lifecycle(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	testFakeWorkspace(f)
+
+	r := resourceArdoqField()
+
+	config := map[string]interface{}{
+		"name":           "status",
+		"label":          "Status",
+		"type":           fieldTypeList,
+		"model":          "model1",
+		"component_type": []interface{}{"p1"},
+		"options":        []interface{}{"Active", "Retired"},
+	}
+
+	state := testApplyResource(t, r, nil, config, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"name":      "status",
+		"options.#": "2",
+		"global":    "false",
+	})
+	testImportResourceVerify(t, r, state, meta)
+
+	config["label"] = "Lifecycle status"
+	config["options"] = []interface{}{"Active", "Phasing out", "Retired"}
+
+	state = testApplyResource(t, r, state, config, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"label":     "Lifecycle status",
+		"options.1": "Phasing out",
+	})
+	testImportResourceVerify(t, r, state, meta)
+
+	testDestroyResource(t, r, state, meta)
+	if field := f.get("field", state.ID); field != nil {
+		t.Errorf("field %s was not deleted", state.ID)
+	}
+}
+
+func TestResourceField_optionsForText(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+
+	_, err := testApplyResourceErr(resourceArdoqField(), nil, map[string]interface{}{
+		"name":    "owner",
+		"label":   "Owner",
+		"type":    fieldTypeText,
+		"model":   "model1",
+		"options": []interface{}{"me"},
+	}, meta)
+	if err == nil {
+		t.Fatal("options for a Text field were accepted")
+	}
+}
@@ -0,0 +1,479 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// modelComponentTypeDepth is the number of levels of nested component types the ardoq_model resource supports
+const modelComponentTypeDepth = 6
+
+func resourceArdoqModel() *schema.Resource {
+	return &schema.Resource{
+		Description:   "`ardoq_model` resource lets you create a model, with its component types and reference types",
+		CreateContext: resourceArdoqModelCreate,
+		ReadContext:   resourceArdoqModelRead,
+		UpdateContext: resourceArdoqModelUpdate,
+		DeleteContext: resourceArdoqModelDelete,
+		CustomizeDiff: resourceArdoqModelCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The unique ID of the model",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"name": {
+				Description: "Name of the model",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"description": {
+				Description: "Text field describing the model",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"component_type": {
+				Description: fmt.Sprintf("Component types of the model, component types can be nested up to %d levels deep, a model with deeper component types can't be read. Names must be unique within the model", modelComponentTypeDepth),
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        modelComponentTypeResource(modelComponentTypeDepth),
+			},
+			"reference_type": {
+				Description: "Reference types of the model. Names must be unique within the model",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Name of the reference type i.e. Synchronous, Implicit etc.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"color": {
+							Description: "Color of the reference line, i.e. `#000000`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"line": {
+							Description: "Line style of the reference i.e. `solid`, `dashed` or `dotted`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"line_beginning": {
+							Description: "Arrow style at the beginning of the reference line",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"line_ending": {
+							Description: "Arrow style at the end of the reference line",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"return_name": {
+							Description: "Name of the reference type when it is read from target to source, i.e. `Is used by` for `Uses`",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"component_types": {
+				Description: "A map of the names of all component types and their id's, use it to set `type_id` of an `ardoq_component`",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"reference_types": {
+				Description: "A map of the names of all reference types and their id's",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// modelComponentTypeResource returns the schema of a component type block,
+// the schema has no recursion so the children are nested up to depth levels
+func modelComponentTypeResource(depth int) *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Description: "Name of the component type",
+			Type:        schema.TypeString,
+			Required:    true,
+		},
+		"color": {
+			Description: "Color of the component type, i.e. `#ff0000`",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"icon": {
+			Description: "Icon of the component type",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"image": {
+			Description: "Image of the component type, used instead of the icon",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"shape": {
+			Description: "Shape of the component type",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+	}
+
+	if depth > 1 {
+		s["component_type"] = &schema.Schema{
+			Description: "Child component types",
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        modelComponentTypeResource(depth - 1),
+		}
+	}
+
+	return &schema.Resource{Schema: s}
+}
+
+func resourceArdoqModelCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+
+	model, err := c.Models().Create(ctx, expandModelDefinition(d, nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(model.ID)
+
+	return resourceArdoqModelRead(ctx, d, m)
+}
+
+func resourceArdoqModelRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c := m.(*apiClient)
+
+	model, err := c.Models().ReadDefinition(ctx, d.Id())
+	if err != nil {
+		return handleNotFoundError(err, d, d.Id())
+	}
+
+	// the deeper component types can't be stored in the state, the next apply would delete them
+	if depth := modelComponentTypesDepth(model.Root); depth > modelComponentTypeDepth {
+		return diag.Errorf("model %s has component types nested %d levels deep, ardoq_model supports up to %d levels. "+
+			"Applying it would delete the deeper component types, manage the model in Ardoq instead", d.Id(), depth, modelComponentTypeDepth)
+	}
+
+	flatModel := flattenModelDefinition(model)
+
+	for key, val := range flatModel {
+		if err := d.Set(key, val); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
+
+func resourceArdoqModelUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+	id := d.Id()
+
+	// read the current model, so existing component and reference types keep their id
+	existing, err := c.Models().ReadDefinition(ctx, id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = c.Models().Update(ctx, id, expandModelDefinition(d, existing))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceArdoqModelRead(ctx, d, m)
+}
+
+func resourceArdoqModelDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+
+	err := c.Models().Delete(ctx, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return diag.Diagnostics{}
+}
+
+// resourceArdoqModelCustomizeDiff checks the names of the types are unique, and
+// predicts the computed component_types and reference_types when no new types are added
+func resourceArdoqModelCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	componentTypeNames := modelComponentTypeNames(d.Get("component_type").([]interface{}))
+	if d.NewValueKnown("component_type") {
+		if err := checkUniqueNames("component type", componentTypeNames); err != nil {
+			return err
+		}
+	}
+
+	var referenceTypeNames []string
+	for _, v := range d.Get("reference_type").([]interface{}) {
+		referenceTypeNames = append(referenceTypeNames, v.(map[string]interface{})["name"].(string))
+	}
+	if d.NewValueKnown("reference_type") {
+		if err := checkUniqueNames("reference type", referenceTypeNames); err != nil {
+			return err
+		}
+	}
+
+	if err := setNewTypeIDs(d, "component_type", "component_types", componentTypeNames); err != nil {
+		return err
+	}
+
+	return setNewTypeIDs(d, "reference_type", "reference_types", referenceTypeNames)
+}
+
+// setNewTypeIDs sets the computed map of type ids when the types change, when all
+// names already have an id the new map is known at plan time, otherwise it's computed
+func setNewTypeIDs(d *schema.ResourceDiff, key, computedKey string, names []string) error {
+	if !d.HasChange(key) {
+		return nil
+	}
+
+	old, _ := d.GetChange(computedKey)
+	ids, ok := filterKnownIDs(old.(map[string]interface{}), names)
+	if !d.NewValueKnown(key) || !ok {
+		return d.SetNewComputed(computedKey)
+	}
+
+	return d.SetNew(computedKey, ids)
+}
+
+// filterKnownIDs returns the ids of the names, ok is false when one of the names has no id yet
+func filterKnownIDs(ids map[string]interface{}, names []string) (map[string]interface{}, bool) {
+	result := make(map[string]interface{})
+	for _, name := range names {
+		id, ok := ids[name]
+		if !ok {
+			return nil, false
+		}
+		result[name] = id
+	}
+
+	return result, true
+}
+
+func checkUniqueNames(kind string, names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("%s name %q is used more than once, names must be unique within a model", kind, name)
+		}
+		seen[name] = true
+	}
+
+	return nil
+}
+
+// modelComponentTypeNames returns the names of all the nested component type blocks
+func modelComponentTypeNames(componentTypes []interface{}) []string {
+	var result []string
+	for _, v := range componentTypes {
+		ct := v.(map[string]interface{})
+		result = append(result, ct["name"].(string))
+		if children, ok := ct["component_type"]; ok {
+			result = append(result, modelComponentTypeNames(children.([]interface{}))...)
+		}
+	}
+
+	return result
+}
+
+// expandModelDefinition builds the model payload from the configuration, component and reference types
+// that already exist in the existing model keep their id, new ones get a new id
+func expandModelDefinition(d *schema.ResourceData, existing *modelDefinition) modelDefinition {
+	componentTypeIDs := make(map[string]string)
+	referenceTypeIDs := make(map[string]int)
+	maxReferenceTypeID := -1
+
+	if existing != nil {
+		componentTypeIDs = modelComponentTypeIDs(existing.Root)
+		for _, rt := range existing.ReferenceTypes {
+			referenceTypeIDs[rt.Name] = rt.ID
+			if rt.ID > maxReferenceTypeID {
+				maxReferenceTypeID = rt.ID
+			}
+		}
+	}
+
+	// Ardoq component type ids are a "p" followed by a timestamp in milliseconds
+	next := time.Now().UnixNano() / int64(time.Millisecond)
+	newComponentTypeID := func() string {
+		next++
+		return fmt.Sprintf("p%d", next)
+	}
+
+	model := modelDefinition{
+		Name:           d.Get("name").(string),
+		Description:    d.Get("description").(string),
+		Root:           expandModelComponentTypes(d.Get("component_type").([]interface{}), 1, componentTypeIDs, newComponentTypeID),
+		ReferenceTypes: make(map[string]modelReferenceType),
+	}
+
+	for _, v := range d.Get("reference_type").([]interface{}) {
+		rt := v.(map[string]interface{})
+
+		id, ok := referenceTypeIDs[rt["name"].(string)]
+		if !ok {
+			maxReferenceTypeID++
+			id = maxReferenceTypeID
+		}
+
+		model.ReferenceTypes[strconv.Itoa(id)] = modelReferenceType{
+			ID:            id,
+			Name:          rt["name"].(string),
+			Color:         rt["color"].(string),
+			Line:          rt["line"].(string),
+			LineBeginning: rt["line_beginning"].(string),
+			LineEnding:    rt["line_ending"].(string),
+			ReturnName:    rt["return_name"].(string),
+		}
+	}
+
+	return model
+}
+
+func expandModelComponentTypes(componentTypes []interface{}, level int, ids map[string]string, newID func() string) map[string]modelComponentType {
+	result := make(map[string]modelComponentType)
+
+	for i, v := range componentTypes {
+		ct := v.(map[string]interface{})
+
+		id, ok := ids[ct["name"].(string)]
+		if !ok {
+			id = newID()
+		}
+
+		var children []interface{}
+		if v, ok := ct["component_type"]; ok {
+			children = v.([]interface{})
+		}
+
+		result[id] = modelComponentType{
+			ID:       id,
+			Name:     ct["name"].(string),
+			Color:    ct["color"].(string),
+			Icon:     ct["icon"].(string),
+			Image:    ct["image"].(string),
+			Shape:    ct["shape"].(string),
+			Index:    i,
+			Level:    level,
+			Children: expandModelComponentTypes(children, level+1, ids, newID),
+		}
+	}
+
+	return result
+}
+
+// modelComponentTypeIDs walks the tree of component types and returns the id of every name
+func modelComponentTypeIDs(componentTypes map[string]modelComponentType) map[string]string {
+	result := make(map[string]string)
+	for _, ct := range componentTypes {
+		result[ct.Name] = ct.ID
+		for k, v := range modelComponentTypeIDs(ct.Children) {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
+func flattenModelDefinition(model *modelDefinition) map[string]interface{} {
+	var referenceTypes []modelReferenceType
+	referenceTypeIDs := make(map[string]string)
+	for _, rt := range model.ReferenceTypes {
+		referenceTypes = append(referenceTypes, rt)
+		referenceTypeIDs[rt.Name] = strconv.Itoa(rt.ID)
+	}
+	sort.Slice(referenceTypes, func(i, j int) bool { return referenceTypes[i].ID < referenceTypes[j].ID })
+
+	var flatReferenceTypes []interface{}
+	for _, rt := range referenceTypes {
+		flatReferenceTypes = append(flatReferenceTypes, map[string]interface{}{
+			"name":           rt.Name,
+			"color":          rt.Color,
+			"line":           rt.Line,
+			"line_beginning": rt.LineBeginning,
+			"line_ending":    rt.LineEnding,
+			"return_name":    rt.ReturnName,
+		})
+	}
+
+	return map[string]interface{}{
+		"id":              model.ID,
+		"name":            model.Name,
+		"description":     model.Description,
+		"component_type":  flattenModelComponentTypes(model.Root, modelComponentTypeDepth),
+		"reference_type":  flatReferenceTypes,
+		"component_types": modelComponentTypeIDs(model.Root),
+		"reference_types": referenceTypeIDs,
+	}
+}
+
+// modelComponentTypesDepth returns the number of levels of nested component types
+func modelComponentTypesDepth(componentTypes map[string]modelComponentType) int {
+	depth := 0
+	for _, ct := range componentTypes {
+		if d := 1 + modelComponentTypesDepth(ct.Children); d > depth {
+			depth = d
+		}
+	}
+
+	return depth
+}
+
+// flattenModelComponentTypes returns the component types ordered by their index,
+// children deeper than depth levels can't be represented in the schema and are left out,
+// resourceArdoqModelRead rejects those models
+func flattenModelComponentTypes(componentTypes map[string]modelComponentType, depth int) []interface{} {
+	var sorted []modelComponentType
+	for _, ct := range componentTypes {
+		sorted = append(sorted, ct)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Index != sorted[j].Index {
+			return sorted[i].Index < sorted[j].Index
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var result []interface{}
+	for _, ct := range sorted {
+		flat := map[string]interface{}{
+			"name":  ct.Name,
+			"color": ct.Color,
+			"icon":  ct.Icon,
+			"image": ct.Image,
+			"shape": ct.Shape,
+		}
+		if depth > 1 {
+			flat["component_type"] = flattenModelComponentTypes(ct.Children, depth-1)
+		}
+		result = append(result, flat)
+	}
+
+	return result
+}
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccResourceModel_basic(t *testing.T) {
+	t.Parallel()
+
+	modelName := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceModel_basic(modelName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ardoq_model.my-model", "name", modelName),
+					resource.TestCheckResourceAttrSet("ardoq_model.my-model", "component_types.Application"),
+					resource.TestCheckResourceAttrSet("ardoq_model.my-model", "component_types.Module"),
+					resource.TestCheckResourceAttr("ardoq_model.my-model", "reference_types.Uses", "0"),
+				),
+			},
+			{
+				ResourceName:      "ardoq_model.my-model",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccResourceModel_update(modelName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("ardoq_model.my-model", "component_types.Application", "ardoq_component.my-component", "type_id"),
+					resource.TestCheckResourceAttr("ardoq_model.my-model", "reference_types.Uses", "0"),
+					resource.TestCheckResourceAttr("ardoq_model.my-model", "reference_types.Implicit", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceModel_basic(modelName string) string {
+	return fmt.Sprintf(`
+resource "ardoq_model" "my-model" {
+  name = "%s"
+
+  component_type {
+    name  = "Application"
+    color = "#ff0000"
+
+    component_type {
+      name = "Module"
+    }
+  }
+
+  reference_type {
+    name        = "Uses"
+    line        = "solid"
+    return_name = "Is used by"
+  }
+}
+`, modelName)
+}
+
+func testAccResourceModel_update(modelName string) string {
+	return fmt.Sprintf(`
+resource "ardoq_model" "my-model" {
+  name        = "%s"
+  description = "TestAcc updated"
+
+  component_type {
+    name  = "Application"
+    color = "#00ff00"
+
+    component_type {
+      name = "Module"
+    }
+  }
+
+  component_type {
+    name  = "Server"
+    shape = "rect"
+  }
+
+  reference_type {
+    name        = "Uses"
+    line        = "solid"
+    return_name = "Is used by"
+  }
+
+  reference_type {
+    name = "Implicit"
+    line = "dashed"
+  }
+}
+
+resource "ardoq_workspace" "my-workspace" {
+  name            = "%[1]s"
+  component_model = ardoq_model.my-model.id
+}
+
+resource "ardoq_component" "my-component" {
+  root_workspace = ardoq_workspace.my-workspace.id
+  name           = "%[1]s"
+  type_id        = ardoq_model.my-model.component_types["Application"]
+}
+`, modelName)
+}
+
+func TestResourceModel_lifecycle(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+
+	r := resourceArdoqModel()
+
+	config := map[string]interface{}{
+		"name": "Test",
+		"component_type": []interface{}{
+			map[string]interface{}{
+				"name":  "Application",
+				"color": "#ff0000",
+				"component_type": []interface{}{
+					map[string]interface{}{"name": "Module"},
+				},
+			},
+		},
+		"reference_type": []interface{}{
+			map[string]interface{}{"name": "Uses", "line": "solid", "return_name": "Is used by"},
+		},
+	}
+
+	state := testApplyResource(t, r, nil, config, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"name":                 "Test",
+		"component_types.%":    "2",
+		"reference_types.Uses": "0",
+	})
+	testImportResourceVerify(t, r, state, meta)
+
+	application := state.Attributes["component_types.Application"]
+
+	config["description"] = "TestAcc updated"
+	config["reference_type"] = append(config["reference_type"].([]interface{}),
+		map[string]interface{}{"name": "Implicit", "line": "dashed"})
+
+	state = testApplyResource(t, r, state, config, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"description":                 "TestAcc updated",
+		"component_types.Application": application,
+		"reference_types.Uses":        "0",
+		"reference_types.Implicit":    "1",
+	})
+	testImportResourceVerify(t, r, state, meta)
+
+	testDestroyResource(t, r, state, meta)
+	if model := f.get("model", state.ID); model != nil {
+		t.Errorf("model %s was not deleted", state.ID)
+	}
+}
+
+func TestResourceModel_tooDeep(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+
+	r := resourceArdoqModel()
+
+	// component types nested one level deeper than the schema supports
+	var root map[string]interface{}
+	for level := modelComponentTypeDepth + 1; level > 0; level-- {
+		id := fmt.Sprintf("p%d", level)
+		root = map[string]interface{}{id: map[string]interface{}{"id": id, "name": fmt.Sprintf("Level %d", level), "level": level - 1, "children": root}}
+	}
+	id := f.put("model", map[string]interface{}{"name": "Deep", "root": root})
+
+	_, diags := r.RefreshWithoutUpgrade(context.Background(), &terraform.InstanceState{ID: id}, meta)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, fmt.Sprintf("nested %d levels deep", modelComponentTypeDepth+1)) {
+		t.Errorf("diagnostics = %v, want an error for the deepest component type", diags)
+	}
+}
@@ -1,12 +1,15 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccResourceReference_basic(t *testing.T) {
@@ -61,3 +64,366 @@ resource "ardoq_reference" "my-reference" {
 }
 `, RootWorkspace, componentName)
 }
+
+func TestResourceReference_clearAttributes(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+
+	workspace := testFakeWorkspace(f)
+	source := f.put("component", map[string]interface{}{"name": "source", "rootWorkspace": workspace})
+	target := f.put("component", map[string]interface{}{"name": "target", "rootWorkspace": workspace})
+
+	r := resourceArdoqReference()
+
+	config := map[string]interface{}{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+		"source":           source,
+		"target":           target,
+		"type":             2,
+	}
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+		"source":           source,
+		"target":           target,
+		"type":             2,
+		"description":      "some description",
+		"display_text":     "uses",
+		"fields_json":      `{"owner": "me", "cost": 10}`,
+	}, meta)
+
+	config["fields_json"] = `{"owner":"me"}`
+	state = testApplyResource(t, r, state, config, meta)
+
+	body := f.lastRequest(t, "PATCH", "reference/"+state.ID).Body
+	if v, ok := body["cost"]; !ok || v != nil {
+		t.Errorf("update request cost = %v, want null", v)
+	}
+	for _, key := range []string{"description", "displayText"} {
+		if v, ok := body[key]; !ok || v != "" {
+			t.Errorf("update request %s = %v, want empty", key, v)
+		}
+	}
+
+	if v, ok := f.get("reference", state.ID)["cost"]; ok {
+		t.Errorf("reference cost = %v, want it cleared", v)
+	}
+
+	testCheckAttributes(t, state, map[string]string{
+		"description":  "",
+		"display_text": "",
+		"fields_json":  `{"owner":"me"}`,
+	})
+}
+
+func TestResourceReference_lifecycle(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	source := f.put("component", map[string]interface{}{"name": "source", "rootWorkspace": workspace})
+	target := f.put("component", map[string]interface{}{"name": "target", "rootWorkspace": workspace})
+
+	r := resourceArdoqReference()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+		"source":           source,
+		"target":           target,
+		"type_name":        "Implicit",
+		"description":      "TestAcc",
+		"fields": map[string]interface{}{
+			"owner": "me",
+		},
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"type":         "2",
+		"type_name":    "Implicit",
+		"description":  "TestAcc",
+		"fields.%":     "1",
+		"fields.owner": "me",
+		"version":      "1",
+		"created_by":   "user1",
+	})
+	// an import can't know type_name was used, it only sets type
+	testImportResourceVerify(t, r, state, meta, "type_name")
+	created := state.Attributes["created"]
+
+	// a refresh only looks up the name of the type when the type changed
+	reads := f.requestCount("GET", "model/model1")
+	state = testRefreshResource(t, r, state, meta)
+	if count := f.requestCount("GET", "model/model1") - reads; count != 0 {
+		t.Errorf("the model was read %d times during refresh, want none", count)
+	}
+	testCheckAttributes(t, state, map[string]string{"type_name": "Implicit"})
+
+	// a type changed in the Ardoq app is looked up
+	model := f.get("model", "model1")
+	model["referenceTypes"].(map[string]interface{})["3"] = map[string]interface{}{"id": 3, "name": "Synchronous"}
+	f.put("model", model)
+	changed := f.get("reference", state.ID)
+	changed["type"] = 3
+	f.put("reference", changed)
+
+	refreshed := testRefreshResource(t, r, state, meta)
+	testCheckAttributes(t, refreshed, map[string]string{"type": "3", "type_name": "Synchronous"})
+	changed["type"] = 2
+	f.put("reference", changed)
+
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+		"source":           source,
+		"target":           target,
+		"type_name":        "Implicit",
+		"description":      "TestAcc updated",
+		"display_text":     "uses",
+		"fields_json":      `{"cost":10,"owner":"you"}`,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"description":  "TestAcc updated",
+		"display_text": "uses",
+		"fields.%":     "0",
+		"fields_json":  `{"cost":10,"owner":"you"}`,
+		"version":      "2",
+		"created":      created,
+	})
+	if state.Attributes["last_updated"] == created {
+		t.Errorf("last_updated = %q, want it to change with the update", state.Attributes["last_updated"])
+	}
+	// an import can't know fields_json was used, so the fields end up in "fields"
+	testImportResourceVerify(t, r, state, meta, "type_name", "fields", "fields_json")
+
+	testDestroyResource(t, r, state, meta)
+	if reference := f.get("reference", state.ID); reference != nil {
+		t.Errorf("reference %s was not deleted", state.ID)
+	}
+}
+
+func TestResourceReference_unknownType(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	source := f.put("component", map[string]interface{}{"name": "source", "rootWorkspace": workspace})
+	target := f.put("component", map[string]interface{}{"name": "target", "rootWorkspace": workspace})
+
+	_, err := testApplyResourceErr(resourceArdoqReference(), nil, map[string]interface{}{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+		"source":           source,
+		"target":           target,
+		"type_name":        "Synchronous",
+	}, meta)
+	if err == nil || !strings.Contains(err.Error(), `reference type "Synchronous" not found`) {
+		t.Fatalf("error = %v, want the type not to be found", err)
+	}
+	if count := f.requestCount("POST", "reference"); count != 0 {
+		t.Errorf("%d references created, want the error during plan", count)
+	}
+}
+
+func TestResourceReference_deletedOutsideTerraform(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	source := f.put("component", map[string]interface{}{"name": "source", "rootWorkspace": workspace})
+	target := f.put("component", map[string]interface{}{"name": "target", "rootWorkspace": workspace})
+
+	r := resourceArdoqReference()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+		"source":           source,
+		"target":           target,
+		"type":             2,
+	}, meta)
+
+	f.delete("reference", state.ID)
+
+	if state := testRefreshResource(t, r, state, meta); state != nil {
+		t.Errorf("state = %v, want the reference removed from the state", state)
+	}
+}
+
+func TestResourceReference_fieldsChangedOutsideTerraform(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	source := f.put("component", map[string]interface{}{"name": "source", "rootWorkspace": workspace})
+	target := f.put("component", map[string]interface{}{"name": "target", "rootWorkspace": workspace})
+
+	r := resourceArdoqReference()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+		"source":           source,
+		"target":           target,
+		"type":             2,
+		"fields": map[string]interface{}{
+			"owner": "me",
+		},
+	}, meta)
+
+	reference := f.get("reference", state.ID)
+	reference["owner"] = "someone else"
+	reference["tags"] = []interface{}{"a", "b"}
+	f.put("reference", reference)
+
+	state = testRefreshResource(t, r, state, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"fields.%":     "2",
+		"fields.owner": "someone else",
+		"fields.tags":  "a, b",
+	})
+}
+
+func TestResourceReference_optimisticConcurrency(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	source := f.put("component", map[string]interface{}{"name": "source", "rootWorkspace": workspace})
+	target := f.put("component", map[string]interface{}{"name": "target", "rootWorkspace": workspace})
+
+	r := resourceArdoqReference()
+
+	config := map[string]interface{}{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+		"source":           source,
+		"target":           target,
+		"type":             2,
+	}
+	state := testApplyResource(t, r, nil, config, meta)
+
+	reference := f.get("reference", state.ID)
+	reference["displayText"] = "changed in the app"
+	reference["_version"] = 2
+	f.put("reference", reference)
+
+	config["description"] = "TestAcc"
+	_, err := testApplyResourceErr(r, state, config, meta)
+	if err == nil || !strings.Contains(err.Error(), "reference modified outside Terraform since plan; re-run plan") {
+		t.Fatalf("got error %v, want a version conflict", err)
+	}
+	if description := f.get("reference", state.ID)["description"]; description != nil {
+		t.Errorf("description = %v, want the reference unchanged", description)
+	}
+}
+
+func TestResourceReference_workspaces(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+	workspace := testFakeWorkspace(f)
+	other := f.put("workspace", map[string]interface{}{"name": "Other", "componentModel": "model1"})
+	source := f.put("component", map[string]interface{}{"name": "source", "rootWorkspace": workspace})
+	target := f.put("component", map[string]interface{}{"name": "target", "rootWorkspace": workspace})
+	remote := f.put("component", map[string]interface{}{"name": "remote", "rootWorkspace": other})
+
+	r := resourceArdoqReference()
+
+	// the workspaces are read from the components
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"source": source,
+		"target": target,
+		"type":   2,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"root_workspace":   workspace,
+		"target_workspace": workspace,
+	})
+	body := f.lastRequest(t, "POST", "reference").Body
+	if body["rootWorkspace"] != workspace || body["targetWorkspace"] != workspace {
+		t.Errorf("create request = %v, want the workspaces of the components", body)
+	}
+
+	// without changes the components are not read again
+	reads := f.requestCount("GET", "component/"+source)
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"source": source,
+		"target": target,
+		"type":   2,
+	}, meta)
+	if count := f.requestCount("GET", "component/"+source) - reads; count != 0 {
+		t.Errorf("the source component was read %d times, want none", count)
+	}
+	if count := f.requestCount("PATCH", "reference/"+state.ID); count != 0 {
+		t.Errorf("the reference was updated %d times, want none", count)
+	}
+
+	for name, tc := range map[string]struct {
+		config map[string]interface{}
+		want   string
+	}{
+		"wrong root_workspace": {
+			config: map[string]interface{}{"source": source, "target": target, "root_workspace": other},
+			want:   "root_workspace " + other + " is not the workspace of the source component " + source,
+		},
+		"wrong target_workspace": {
+			config: map[string]interface{}{"source": source, "target": remote, "target_workspace": workspace},
+			want:   "target_workspace " + workspace + " is not the workspace of the target component " + remote,
+		},
+		"not linked": {
+			config: map[string]interface{}{"source": source, "target": remote},
+			want:   "target_workspace " + other + " is not linked to workspace " + workspace,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.config["type"] = 2
+			if _, err := testApplyResourceErr(r, nil, tc.config, meta); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, want %q", err, tc.want)
+			}
+		})
+	}
+	if count := f.requestCount("POST", "reference"); count != 1 {
+		t.Errorf("%d references created, want the errors during plan", count)
+	}
+
+	// a reference to a linked workspace
+	linked := f.get("workspace", workspace)
+	linked["linked-workspaces"] = map[string]interface{}{"linked": []interface{}{other}}
+	f.put("workspace", linked)
+
+	state = testApplyResource(t, r, nil, map[string]interface{}{
+		"source": source,
+		"target": remote,
+		"type":   2,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"root_workspace":   workspace,
+		"target_workspace": other,
+	})
+
+	// a reference to a workspace which links to the workspace of the source component
+	linked["linked-workspaces"] = map[string]interface{}{"backlinked": []interface{}{other}}
+	f.put("workspace", linked)
+
+	state = testApplyResource(t, r, nil, map[string]interface{}{
+		"source": source,
+		"target": remote,
+		"type":   2,
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"root_workspace":   workspace,
+		"target_workspace": other,
+	})
+
+	// the workspaces of components which are created in the same apply are unknown during plan
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"source": testUnknownValue,
+		"target": target,
+		"type":   2,
+	}), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attribute := diff.Attributes["root_workspace"]; attribute == nil || !attribute.NewComputed {
+		t.Errorf("diff root_workspace = %v, want it to be computed", attribute)
+	}
+	if attribute := diff.Attributes["target_workspace"]; attribute == nil || attribute.New != workspace {
+		t.Errorf("diff target_workspace = %v, want %s", attribute, workspace)
+	}
+}
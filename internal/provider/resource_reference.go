@@ -2,19 +2,26 @@ package provider
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	ardoq "github.com/mories76/ardoq-client-go/pkg"
 )
 
 func resourceArdoqReference() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		Description:   "`ardoq_reference` resource lets you create a reference",
 		CreateContext: resourceArdoqReferenceCreate,
 		ReadContext:   resourceArdoqReferenceRead,
 		UpdateContext: resourceArdoqReferenceUpdate,
 		DeleteContext: resourceArdoqReferenceDelete,
+		CustomizeDiff: customdiff.Sequence(
+			resourceArdoqReferenceResolveWorkspaces,
+			resourceArdoqReferenceResolveType,
+			resourceAuditComputedOnChange,
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -35,45 +42,55 @@ func resourceArdoqReference() *schema.Resource {
 				Computed:    true,
 			},
 			"root_workspace": {
-				Description: "Id of the source component's workspace",
+				Description: "Id of the source component's workspace, it's read from the source component when not set",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 			},
 			"source": {
-				Description: "Id of the source component",
+				Description: "Id of the source component, changing it replaces the reference",
 				Type:        schema.TypeString,
 				Required:    true,
+				ForceNew:    true,
 			},
 			"target": {
-				Description: "Id of the target component",
+				Description: "Id of the target component, changing it replaces the reference",
 				Type:        schema.TypeString,
 				Required:    true,
+				ForceNew:    true,
 			},
 			"target_workspace": {
-				Description: "Id of the target component's workspace",
-				Type:        schema.TypeString,
-				Required:    true,
+				Description: "Id of the target component's workspace, it's read from the target component when not set. " +
+					"A workspace other than the source component's workspace has to be linked with it in Ardoq, in either direction",
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
 			},
 			"type": {
-				Description: "Type (as defined by the model) i.e. Synchronous, Implicit etc.",
-				Type:        schema.TypeInt,
-				Required:    true,
-				// Computed: true,
+				Description:  "Type (as defined by the model) i.e. Synchronous, Implicit etc.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"type", "type_name"},
 			},
-			"fields": {
-				Description: "All custom fields from the model end up here",
-				Type:        schema.TypeMap,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
-				Optional: true,
+			"type_name": {
+				Description:  "Name of the type (as defined by the model) i.e. Synchronous, Implicit etc. The id of the type is looked up in the model of the source component's workspace",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"type", "type_name"},
 			},
+			"fields":      customFieldsSchema(),
+			"fields_json": fieldsJSONSchema(),
 		},
 	}
+	addAuditFieldsToSchema(r.Schema)
+
+	return r
 }
 
 func resourceArdoqReferenceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 
 	// var diags diag.Diagnostics
 
@@ -85,6 +102,30 @@ func resourceArdoqReferenceCreate(ctx context.Context, d *schema.ResourceData, m
 		Type:            d.Get("type").(int),
 	}
 
+	// the workspaces are unknown during plan when the components didn't exist yet
+	var err error
+	if req.RootWorkspace == "" {
+		if req.RootWorkspace, err = componentWorkspace(ctx, c, req.Source.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if req.TargetWorkspace == "" {
+		if req.TargetWorkspace, err = componentWorkspace(ctx, c, req.Target.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// the type could not always be resolved during plan, i.e. because the workspace didn't exist yet
+	if v, ok := d.GetOk("type_name"); ok {
+		model, err := workspaceModel(ctx, c, req.RootWorkspace.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if req.Type, err = referenceTypeID(model, v.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if v, ok := d.GetOk("description"); ok {
 		req.Description = v.(string)
 	}
@@ -93,19 +134,12 @@ func resourceArdoqReferenceCreate(ctx context.Context, d *schema.ResourceData, m
 		req.DisplayText = v.(string)
 	}
 
-	// check if custom fields are specified by checking len of the schema field "fields"
-	// if so, loop map and add each field to the request
-	if len(d.Get("fields").(map[string]interface{})) > 0 {
-		//create temporary map
-		fields := make(map[string]interface{})
-
-		// foreach key and value append to temporary fields maps
-		for k, v := range d.Get("fields").(map[string]interface{}) {
-			fields[k] = v.(string)
-		}
-		// assign temporary fields map to req
-		req.Fields = fields
+	// custom fields are either set as map of strings in "fields" or as JSON in "fields_json"
+	fields, err := expandCustomFields(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	req.Fields = fields
 
 	reference, err := c.References().Create(ctx, req)
 	if err != nil {
@@ -119,21 +153,48 @@ func resourceArdoqReferenceCreate(ctx context.Context, d *schema.ResourceData, m
 
 func resourceArdoqReferenceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 
 	reference, err := c.References().Read(ctx, d.Id())
 	if err != nil {
 		return handleNotFoundError(err, d, d.Id())
 	}
 
+	// the type in the state, before it's overwritten with the type read from Ardoq
+	oldType := d.Get("type").(int)
+
 	flatRefence := flattenReference(reference)
 
+	// the custom fields are set on the attribute used in the configuration by setCustomFields
+	delete(flatRefence, "fields")
+	delete(flatRefence, "fields_json")
+
 	for key, val := range flatRefence {
 		if err := d.Set(key, val); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
+	if err := setCustomFields(d, reference.Fields); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the name of the type is only looked up when it's used and the type was changed outside Terraform,
+	// otherwise type_name still names the type, so a refresh doesn't read the workspace and model
+	if d.Get("type_name").(string) != "" && reference.Type != oldType {
+		model, err := workspaceModel(ctx, c, reference.RootWorkspace)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		typeName, err := referenceTypeName(model, reference.Type)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("type_name", typeName); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return diags
 }
 
@@ -141,7 +202,7 @@ func resourceArdoqReferenceUpdate(ctx context.Context, d *schema.ResourceData, m
 	// Warning or errors can be collected in a slice type
 	// var diags diag.Diagnostics
 
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 	id := d.Id()
 
 	// create new request for update
@@ -160,38 +221,26 @@ func resourceArdoqReferenceUpdate(ctx context.Context, d *schema.ResourceData, m
 		req.Type = d.Get("type").(int)
 	}
 
-	if d.HasChange("source") {
-		req.Source = d.Get("source").(string)
-	}
-
-	if d.HasChange("target") {
-		req.Target = d.Get("target").(string)
+	// custom fields are either set as map of strings in "fields" or as JSON in "fields_json",
+	// removed custom fields are sent as null
+	fields, err := expandChangedCustomFields(ctx, c, d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	req.Fields = fields
 
-	// check if custom fields are specified by checking len of the schema field "fields"
-	// if so, loop map and add each field to the request
-	if len(d.Get("fields").(map[string]interface{})) > 0 {
-		//create temporary map
-		fields := make(map[string]interface{})
-
-		// foreach key and value append to temporary fields maps
-		for k, v := range d.Get("fields").(map[string]interface{}) {
-			fields[k] = v.(string)
-		}
-		// assign temporary fields map to req
-		req.Fields = fields
-	}
+	expectVersion(c, d, req.Fields)
 
-	_, err := c.References().Update(ctx, id, req)
+	_, err = c.References().Update(ctx, id, req)
 	if err != nil {
-		return diag.FromErr(err)
+		return updateError(c, "reference", err)
 	}
 
 	return resourceArdoqReferenceRead(ctx, d, m)
 }
 
 func resourceArdoqReferenceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(ardoq.Client)
+	c := m.(*apiClient)
 	id := d.Id()
 
 	err := c.References().Delete(ctx, id)
@@ -202,3 +251,136 @@ func resourceArdoqReferenceDelete(ctx context.Context, d *schema.ResourceData, m
 	d.SetId("")
 	return diag.Diagnostics{}
 }
+
+// resourceArdoqReferenceResolveType sets type to the id of the type named in type_name,
+// or checks the configured type exists in the model of the source component's workspace
+func resourceArdoqReferenceResolveType(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*apiClient)
+
+	typeName := d.Get("type_name").(string)
+
+	// type_name is used, unless the configured type changes
+	resolveName := typeName != "" && d.NewValueKnown("type_name") && !d.HasChange("type")
+	validateType := !resolveName && d.NewValueKnown("type") && (d.Id() == "" || d.HasChange("type"))
+
+	// only look up the type when it might change, this saves requests on every plan
+	if resolveName && d.Id() != "" && !d.HasChanges("type_name", "root_workspace") {
+		return nil
+	}
+	if !resolveName && !validateType {
+		return nil
+	}
+
+	if !d.NewValueKnown("root_workspace") {
+		if resolveName {
+			return d.SetNewComputed("type")
+		}
+		return nil
+	}
+
+	model, err := workspaceModel(ctx, c, d.Get("root_workspace").(string))
+	if err != nil {
+		return err
+	}
+
+	if resolveName {
+		typeID, err := referenceTypeID(model, typeName)
+		if err != nil {
+			return err
+		}
+		return d.SetNew("type", typeID)
+	}
+
+	if _, err := referenceTypeName(model, d.Get("type").(int)); err != nil {
+		return err
+	}
+
+	// the name of the type is only tracked when it was used before
+	if typeName != "" {
+		return d.SetNewComputed("type_name")
+	}
+
+	return nil
+}
+
+// resourceArdoqReferenceResolveWorkspaces sets root_workspace and target_workspace to the workspaces of the
+// source and target components, a configured workspace has to be the workspace of its component.
+// A reference to another workspace needs a link between that workspace and the source component's workspace, in either direction
+func resourceArdoqReferenceResolveWorkspaces(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*apiClient)
+
+	// only read the components when the workspaces might change, this saves requests on every plan
+	if d.Id() != "" && !d.HasChanges("source", "target", "root_workspace", "target_workspace") {
+		return nil
+	}
+
+	if err := resolveReferenceWorkspace(ctx, c, d, "source", "root_workspace"); err != nil {
+		return err
+	}
+	if err := resolveReferenceWorkspace(ctx, c, d, "target", "target_workspace"); err != nil {
+		return err
+	}
+
+	if !d.NewValueKnown("root_workspace") || !d.NewValueKnown("target_workspace") {
+		return nil
+	}
+
+	rootWorkspace := d.Get("root_workspace").(string)
+	targetWorkspace := d.Get("target_workspace").(string)
+	if rootWorkspace == targetWorkspace {
+		return nil
+	}
+
+	workspace, err := c.Workspaces().Get(ctx, rootWorkspace)
+	if err != nil {
+		return fmt.Errorf("could not read workspace %s to validate target_workspace: %w", rootWorkspace, err)
+	}
+	// the workspaces can be linked either way, a workspace lists the workspaces linked to it as back linked
+	links := workspace.LinkedWorkspaces
+	if !containsString(links.Linked, targetWorkspace) && !containsString(links.BackLinked, targetWorkspace) {
+		return fmt.Errorf("target_workspace %s is not linked to workspace %s of the source component, "+
+			"link the workspaces in Ardoq to create references between them", targetWorkspace, rootWorkspace)
+	}
+
+	return nil
+}
+
+// resolveReferenceWorkspace sets the workspace attribute to the workspace of the component, or checks
+// the configured workspace is the workspace of the component
+func resolveReferenceWorkspace(ctx context.Context, c *apiClient, d *schema.ResourceDiff, componentKey, workspaceKey string) error {
+	// a workspace which isn't set is unknown when the reference is created, and the state has the
+	// workspace of the previous component, so only a known new or changed value is configured
+	configured := ""
+	if d.NewValueKnown(workspaceKey) && (d.Id() == "" || d.HasChange(workspaceKey)) {
+		configured = d.Get(workspaceKey).(string)
+	}
+
+	if !d.NewValueKnown(componentKey) {
+		if configured == "" {
+			return d.SetNewComputed(workspaceKey)
+		}
+		return nil
+	}
+
+	id := d.Get(componentKey).(string)
+	workspace, err := componentWorkspace(ctx, c, id)
+	if err != nil {
+		return err
+	}
+
+	if configured != "" && configured != workspace {
+		return fmt.Errorf("%s %s is not the workspace of the %s component %s, which is in workspace %s", workspaceKey, configured, componentKey, id, workspace)
+	}
+
+	return d.SetNew(workspaceKey, workspace)
+}
+
+// componentWorkspace returns the id of the workspace of a component
+func componentWorkspace(ctx context.Context, c *apiClient, id string) (string, error) {
+	component, err := c.Components().Read(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("could not read component %s to find its workspace: %w", id, err)
+	}
+
+	return component.RootWorkspace, nil
+}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceArdoqWorkspace() *schema.Resource {
+	return &schema.Resource{
+		Description:   "`ardoq_workspace` resource lets you create a workspace",
+		CreateContext: resourceArdoqWorkspaceCreate,
+		ReadContext:   resourceArdoqWorkspaceRead,
+		UpdateContext: resourceArdoqWorkspaceUpdate,
+		DeleteContext: resourceArdoqWorkspaceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The unique ID of the workspace",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"name": {
+				Description: "Name of workspace",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"description": {
+				Description: "Text field describing the workspace",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"component_counter": {
+				Description: "Number of components in the workspace",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"component_model": {
+				Description: "Id of the model the workspace is based on. Changing this forces a new workspace",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"component_template": {
+				Description: "Id of the template the workspace is based on. Changing this forces a new workspace",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"fields": {
+				Description: "All custom fields from the model end up here",
+				Type:        schema.TypeMap,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+			},
+		},
+	}
+}
+
+func resourceArdoqWorkspaceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+
+	req := workspaceRequest{
+		Name: d.Get("name").(string),
+	}
+
+	if v, ok := d.GetOk("description"); ok {
+		req.Description = v.(string)
+	}
+
+	if v, ok := d.GetOk("component_model"); ok {
+		req.ComponentModel = v.(string)
+	}
+
+	if v, ok := d.GetOk("component_template"); ok {
+		req.ComponentTemplate = v.(string)
+	}
+
+	// check if custom fields are specified by checking len of the schema field "fields"
+	// if so, loop map and add each field to the request
+	if len(d.Get("fields").(map[string]interface{})) > 0 {
+		fields := make(map[string]interface{})
+		for k, v := range d.Get("fields").(map[string]interface{}) {
+			fields[k] = v.(string)
+		}
+		req.Fields = fields
+	}
+
+	workspace, err := c.Workspaces().Create(ctx, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(workspace.ID)
+
+	return resourceArdoqWorkspaceRead(ctx, d, m)
+}
+
+func resourceArdoqWorkspaceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c := m.(*apiClient)
+
+	workspace, err := c.Workspaces().Get(ctx, d.Id())
+	if err != nil {
+		return handleNotFoundError(err, d, d.Id())
+	}
+
+	flatWorkspace := flattenWorkspace(workspace)
+
+	for key, val := range flatWorkspace {
+		if err := d.Set(key, val); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
+
+func resourceArdoqWorkspaceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+	id := d.Id()
+
+	// create new request for update
+	req := workspaceRequest{}
+
+	// update field if changes are detected
+	if d.HasChange("name") {
+		req.Name = d.Get("name").(string)
+	}
+
+	if d.HasChange("description") {
+		req.Description = d.Get("description").(string)
+	}
+
+	// removed fields are sent as null, otherwise Ardoq keeps their old value
+	if d.HasChange("fields") {
+		oldFields, newFields := d.GetChange("fields")
+		req.Fields = nullRemovedFields(newFields.(map[string]interface{}), oldFields.(map[string]interface{}))
+	}
+
+	_, err := c.Workspaces().Update(ctx, id, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceArdoqWorkspaceRead(ctx, d, m)
+}
+
+func resourceArdoqWorkspaceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*apiClient)
+
+	err := c.Workspaces().Delete(ctx, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccResourceWorkspace_basic(t *testing.T) {
+	t.Parallel()
+
+	ComponentModel := os.Getenv("ARDOQ_MODEL")
+
+	if ComponentModel == "" {
+		t.Skip("ARDOQ_MODEL needs to be set to run this test")
+	}
+
+	workspaceName := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceWorkspace_basic(ComponentModel, workspaceName, "TestAcc"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ardoq_workspace.my-workspace", "name", workspaceName),
+					resource.TestCheckResourceAttr("ardoq_workspace.my-workspace", "component_model", ComponentModel),
+				),
+			},
+			{
+				ResourceName:      "ardoq_workspace.my-workspace",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccResourceWorkspace_basic(ComponentModel, workspaceName, "TestAcc updated"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("ardoq_workspace.my-workspace", "description", "TestAcc updated"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceWorkspace_basic(ComponentModel, workspaceName, description string) string {
+	return fmt.Sprintf(`
+resource "ardoq_workspace" "my-workspace" {
+  name            = "%s"
+  description     = "%s"
+  component_model = "%s"
+}
+`, workspaceName, description, ComponentModel)
+}
+
+func TestResourceWorkspace_clearFields(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+
+	r := resourceArdoqWorkspace()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"name":            "Test",
+		"component_model": "model1",
+		"fields": map[string]interface{}{
+			"owner": "me",
+			"team":  "us",
+		},
+	}, meta)
+
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"name":            "Test",
+		"component_model": "model1",
+		"fields": map[string]interface{}{
+			"owner": "me",
+		},
+	}, meta)
+
+	if v, ok := f.lastRequest(t, "PATCH", "workspace/"+state.ID).Body["team"]; !ok || v != nil {
+		t.Errorf("update request team = %v, want null", v)
+	}
+
+	if v, ok := f.get("workspace", state.ID)["team"]; ok {
+		t.Errorf("workspace team = %v, want it cleared", v)
+	}
+
+	testCheckAttributes(t, state, map[string]string{
+		"fields.%": "1",
+	})
+}
+
+func TestResourceWorkspace_lifecycle(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+
+	r := resourceArdoqWorkspace()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"name":            "Test",
+		"description":     "TestAcc",
+		"component_model": "model1",
+		"fields": map[string]interface{}{
+			"owner": "me",
+		},
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"name":            "Test",
+		"component_model": "model1",
+		"fields.owner":    "me",
+	})
+	testImportResourceVerify(t, r, state, meta)
+
+	state = testApplyResource(t, r, state, map[string]interface{}{
+		"name":            "Test updated",
+		"component_model": "model1",
+	}, meta)
+	testCheckAttributes(t, state, map[string]string{
+		"name":        "Test updated",
+		"description": "",
+		"fields.%":    "0",
+	})
+	testImportResourceVerify(t, r, state, meta)
+
+	testDestroyResource(t, r, state, meta)
+	if workspace := f.get("workspace", state.ID); workspace != nil {
+		t.Errorf("workspace %s was not deleted", state.ID)
+	}
+}
+
+func TestResourceWorkspace_deletedOutsideTerraform(t *testing.T) {
+	f := newFakeArdoq(t)
+	meta := testFakeMeta(t, f)
+
+	r := resourceArdoqWorkspace()
+
+	state := testApplyResource(t, r, nil, map[string]interface{}{
+		"name":            "Test",
+		"component_model": "model1",
+	}, meta)
+
+	f.delete("workspace", state.ID)
+
+	if state := testRefreshResource(t, r, state, meta); state != nil {
+		t.Errorf("state = %v, want the workspace removed from the state", state)
+	}
+}
@@ -1,7 +1,9 @@
 package provider
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -26,3 +28,41 @@ func handleNotFoundError(err error, d *schema.ResourceData, resource string) dia
 
 	return diag.Errorf("Error when reading or editing %s: %s", resource, err.Error())
 }
+
+// convertFields converts the custom fields of an Ardoq object to strings, the same way
+// ardoq.Component.GetConvertedFields() does, so they fit in a TypeMap of TypeString.
+// Empty fields are left out, and lists (i.e. "Select multiple list") become comma separated
+func convertFields(fields map[string]interface{}) map[string]string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for k, v := range fields {
+		switch v := v.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			result[k] = strings.Join(items, ", ")
+		default:
+			result[k] = fmt.Sprint(v)
+		}
+	}
+
+	return result
+}
+
+// expandStringList converts a list or set from the schema to a []string, an empty
+// list results in an empty slice instead of nil, so it can be used to clear a list
+func expandStringList(list []interface{}) []string {
+	result := make([]string, 0, len(list))
+	for _, v := range list {
+		result = append(result, v.(string))
+	}
+
+	return result
+}